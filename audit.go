@@ -0,0 +1,335 @@
+// Package golog Simple flexible go logging
+// This file contains the tamper-evident audit log sink & its verification
+package golog
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrAuditTampered is returned by VerifyAudit when a line of an audit log was modified,
+	// removed or inserted, or a checkpoint signature is invalid
+	ErrAuditTampered = errors.New("golog: audit log tampered")
+
+	// ErrAuditTruncated is returned by VerifyAudit when an audit log does not end with a
+	// checkpoint, as when it was truncated or its sink was not closed
+	ErrAuditTruncated = errors.New("golog: audit log truncated")
+)
+
+// auditGenesis is the prev hash of the first line of an audit log
+var auditGenesis = strings.Repeat("0", sha256.Size*2)
+
+// auditHashKey starts the hash of a line, which ends it
+const auditHashKey = `,"hash":"`
+
+// AuditOptions configure an AuditSink
+type AuditOptions struct {
+	Path            string             // file the records are appended to, created if needed
+	PrivateKey      ed25519.PrivateKey // signs the checkpoints
+	CheckpointEvery int                // records between checkpoints, defaults to 100
+}
+
+// auditFile is the file of an AuditSink, an *os.File
+type auditFile interface {
+	io.ReadWriteCloser
+	Name() string
+	Sync() error
+	Truncate(size int64) error
+}
+
+// AuditSink appends records to a tamper-evident audit log. Each line is a JSON object holding
+// the SHA-256 hash of the previous line ("prev") and its own hash ("hash"), so modifying,
+// removing or inserting a line breaks the chain. Every CheckpointEvery records, and on Close,
+// a checkpoint line signs the hash of the previous line with Ed25519, covering the whole log
+// before it. VerifyAudit checks a log with the public key
+type AuditSink struct {
+	mu       sync.Mutex
+	file     auditFile
+	size     int64 // of the file up to its last complete line
+	key      ed25519.PrivateKey
+	every    int
+	seq      uint64 // of the last line
+	prev     string // hash of the last line
+	unsigned int    // records since the last checkpoint
+	failed   error  // a partial line could not be removed, the log can no longer be chained
+	buf      []byte
+}
+
+// NewAuditSink opens the audit log of the options, continuing the chain of an existing log once
+// it is verified with the public key of PrivateKey
+func NewAuditSink(opts *AuditOptions) (*AuditSink, error) {
+	if len(opts.PrivateKey) != ed25519.PrivateKeySize {
+		return nil, errors.New("golog: audit sink needs an ed25519 private key")
+	}
+	file, err := os.OpenFile(opts.Path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	a := &AuditSink{file: file, key: opts.PrivateKey, every: opts.CheckpointEvery, prev: auditGenesis}
+	if a.every <= 0 {
+		a.every = 100
+	}
+	if err := a.resume(); err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	return a, nil
+}
+
+// resume verifies the chain & checkpoints of an existing log and continues it after its last line
+func (a *AuditSink) resume() error {
+	report := &AuditReport{}
+	pub := a.key.Public().(ed25519.PublicKey)
+	r := bufio.NewReader(a.file)
+	for n := 1; ; n++ {
+		line, err := r.ReadBytes('\n')
+		if len(line) > 0 && line[len(line)-1] != '\n' {
+			return fmt.Errorf("%w: %s ends with a partial line", ErrAuditTruncated, a.file.Name())
+		}
+		if len(line) > 0 {
+			if verr := verifyAuditLine(report, line, pub, &a.prev, &a.seq); verr != nil {
+				return fmt.Errorf("%s: line %d: %w", a.file.Name(), n, verr)
+			}
+			a.size += int64(len(line))
+		}
+		if err == io.EOF {
+			a.unsigned = report.Unsigned
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// Write appends a record to the log, followed by a checkpoint every CheckpointEvery records
+func (a *AuditSink) Write(info *Info) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.failed != nil {
+		return a.failed
+	}
+
+	buf := append(a.buf[:0], '{')
+	enc := jsonEncoder{buf: buf, start: 0}
+	enc.AddUint64("seq", a.seq+1)
+	enc.AddString("time", info.Timestamp.UTC().Format(time.RFC3339Nano))
+	enc.AddString("level", info.Level.String())
+	enc.AddString("module", info.Module)
+	enc.AddString("caller", info.Filename+":"+strconv.Itoa(info.Line))
+	enc.AddString("msg", info.Message)
+	if len(info.Fields) > 0 {
+		enc.key("fields")
+		enc.buf = appendFieldsJSON(enc.buf, info.Fields)
+	}
+	if info.TraceID != "" {
+		enc.AddString("trace_id", info.TraceID)
+		enc.AddString("span_id", info.SpanID)
+	}
+	a.buf = enc.buf
+	if err := a.writeLine(); err != nil {
+		return err
+	}
+	if a.unsigned++; a.unsigned >= a.every {
+		return a.checkpoint()
+	}
+	return nil
+}
+
+// writeLine chains the line in buf, which holds its opening fields, and writes it. The part of
+// a failed write is removed so the next line chains to the last complete one
+func (a *AuditSink) writeLine() error {
+	buf := append(a.buf, `,"prev":"`...)
+	buf = append(buf, a.prev...)
+	buf = append(buf, '"')
+	sum := sha256.Sum256(buf)
+	hash := hex.EncodeToString(sum[:])
+	buf = append(buf, auditHashKey...)
+	buf = append(buf, hash...)
+	buf = append(buf, "\"}\n"...)
+	a.buf = buf
+
+	if _, err := a.file.Write(buf); err != nil {
+		if terr := a.file.Truncate(a.size); terr != nil {
+			a.failed = fmt.Errorf("golog: audit log %s has a partial line: %v", a.file.Name(), terr)
+		}
+		return err
+	}
+	a.size += int64(len(buf))
+	a.seq++
+	a.prev = hash
+	return nil
+}
+
+// auditCheckpointMessage returns the message signed by a checkpoint
+func auditCheckpointMessage(seq uint64, prev string) []byte {
+	return []byte("golog audit checkpoint " + strconv.FormatUint(seq, 10) + " " + prev)
+}
+
+// checkpoint appends a checkpoint signing the hash of the last line, then syncs the file
+func (a *AuditSink) checkpoint() error {
+	seq := a.seq + 1
+	sig := ed25519.Sign(a.key, auditCheckpointMessage(seq, a.prev))
+
+	enc := jsonEncoder{buf: append(a.buf[:0], '{'), start: 0}
+	enc.AddUint64("seq", seq)
+	enc.AddString("time", time.Now().UTC().Format(time.RFC3339Nano))
+	enc.AddString("checkpoint", base64.StdEncoding.EncodeToString(sig))
+	a.buf = enc.buf
+	if err := a.writeLine(); err != nil {
+		return err
+	}
+	a.unsigned = 0
+	return a.file.Sync()
+}
+
+// Checkpoint appends a checkpoint if records were written since the last one, for logs
+// checkpointed on a schedule in addition to CheckpointEvery
+func (a *AuditSink) Checkpoint() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.failed != nil {
+		return a.failed
+	}
+	if a.unsigned == 0 {
+		return nil
+	}
+	return a.checkpoint()
+}
+
+// Close appends a final checkpoint and closes the file
+func (a *AuditSink) Close() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	err := a.failed
+	if err == nil && (a.unsigned > 0 || a.seq == 0) {
+		err = a.checkpoint()
+	}
+	if cerr := a.file.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// auditLine is the chaining of a line of an audit log
+type auditLine struct {
+	Seq        uint64 `json:"seq"`
+	Prev       string `json:"prev"`
+	Checkpoint string `json:"checkpoint"`
+	hash       string
+	body       []byte // the line up to its hash
+}
+
+// parseAuditLine parses a line of an audit log, without checking its hash
+func parseAuditLine(line []byte) (*auditLine, error) {
+	line = bytes.TrimSuffix(line, []byte("\n"))
+	i := bytes.LastIndex(line, []byte(auditHashKey))
+	if i < 0 || len(line)-i != len(auditHashKey)+len(auditGenesis)+2 || !bytes.HasSuffix(line, []byte(`"}`)) {
+		return nil, fmt.Errorf("%w: malformed line", ErrAuditTampered)
+	}
+	l := &auditLine{body: line[:i], hash: string(line[i+len(auditHashKey) : len(line)-2])}
+	if err := json.Unmarshal(append(l.body[:len(l.body):len(l.body)], '}'), l); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrAuditTampered, err)
+	}
+	return l, nil
+}
+
+// AuditReport describes a verified audit log
+type AuditReport struct {
+	Records        int       // records of the log
+	Checkpoints    int       // valid checkpoints of the log
+	LastCheckpoint uint64    // sequence of the last checkpoint, 0 if none
+	LastSigned     time.Time // time of the last checkpoint
+	Unsigned       int       // records after the last checkpoint
+}
+
+// VerifyAudit checks the chain & checkpoints of an audit log written by an AuditSink, whose
+// checkpoints are signed by the private key of pub. It returns ErrAuditTampered, wrapped with
+// the faulty line, when a line was modified, removed or inserted, and ErrAuditTruncated when
+// the log does not end with a checkpoint. Truncating a log at a checkpoint leaves a valid log,
+// compare the report with the expected last checkpoint to detect it
+func VerifyAudit(r io.Reader, pub ed25519.PublicKey) (*AuditReport, error) {
+	report := &AuditReport{}
+	prev, seq := auditGenesis, uint64(0)
+	br := bufio.NewReader(r)
+	for n := 1; ; n++ {
+		line, err := br.ReadBytes('\n')
+		if len(line) > 0 && line[len(line)-1] != '\n' {
+			return report, fmt.Errorf("%w: line %d is partial", ErrAuditTruncated, n)
+		}
+		if len(line) > 0 {
+			if err := verifyAuditLine(report, line, pub, &prev, &seq); err != nil {
+				return report, fmt.Errorf("line %d: %w", n, err)
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return report, err
+		}
+	}
+	if report.Unsigned > 0 || report.Checkpoints == 0 {
+		return report, fmt.Errorf("%w: %d records after the last checkpoint", ErrAuditTruncated, report.Unsigned)
+	}
+	return report, nil
+}
+
+// verifyAuditLine checks a line chains to the previous one, prev & seq, and updates them
+func verifyAuditLine(report *AuditReport, line []byte, pub ed25519.PublicKey, prev *string, seq *uint64) error {
+	l, err := parseAuditLine(line)
+	if err != nil {
+		return err
+	}
+	sum := sha256.Sum256(l.body)
+	switch {
+	case hex.EncodeToString(sum[:]) != l.hash:
+		return fmt.Errorf("%w: hash mismatch", ErrAuditTampered)
+	case l.Seq != *seq+1:
+		return fmt.Errorf("%w: sequence %d follows %d", ErrAuditTampered, l.Seq, *seq)
+	case l.Prev != *prev:
+		return fmt.Errorf("%w: broken chain", ErrAuditTampered)
+	}
+
+	if l.Checkpoint == "" {
+		report.Records++
+		report.Unsigned++
+	} else {
+		sig, err := base64.StdEncoding.DecodeString(l.Checkpoint)
+		if err != nil || !ed25519.Verify(pub, auditCheckpointMessage(l.Seq, l.Prev), sig) {
+			return fmt.Errorf("%w: invalid checkpoint signature", ErrAuditTampered)
+		}
+		var c struct {
+			Time time.Time `json:"time"`
+		}
+		_ = json.Unmarshal(append(l.body[:len(l.body):len(l.body)], '}'), &c)
+		report.Checkpoints++
+		report.LastCheckpoint, report.LastSigned, report.Unsigned = l.Seq, c.Time, 0
+	}
+	*prev, *seq = l.hash, l.Seq
+	return nil
+}
+
+// VerifyAuditFile checks the audit log of a file, see VerifyAudit
+func VerifyAuditFile(path string, pub ed25519.PublicKey) (*AuditReport, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return VerifyAudit(f, pub)
+}
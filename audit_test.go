@@ -0,0 +1,135 @@
+package golog
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeAudit(t *testing.T, path string, key ed25519.PrivateKey, msgs ...string) {
+	t.Helper()
+	sink, err := NewAuditSink(&AuditOptions{Path: path, PrivateKey: key, CheckpointEvery: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := NewLogger(&Options{Module: "audit", Out: &bytes.Buffer{}, Levels: LevelOverrides{}, IDs: IDPerLogger})
+	l.SetEnvironment(EnvDevelopment)
+	l.AddSink(sink)
+	for _, msg := range msgs {
+		l.With(String("user", "ann")).Notice(msg)
+	}
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAuditSink(t *testing.T) {
+	key := ed25519.NewKeyFromSeed(bytes.Repeat([]byte{7}, ed25519.SeedSize))
+	pub := key.Public().(ed25519.PublicKey)
+	path := filepath.Join(t.TempDir(), "audit.log")
+
+	writeAudit(t, path, key, "login", "grant admin", "export")
+	writeAudit(t, path, key, "logout")
+	report, err := VerifyAuditFile(path, pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// login, grant admin, checkpoint, export, checkpoint, logout, checkpoint
+	if report.Records != 4 || report.Checkpoints != 3 || report.LastCheckpoint != 7 || report.Unsigned != 0 {
+		t.Errorf("Unexpected report %+v", report)
+	}
+
+	data, _ := os.ReadFile(path)
+	lines := strings.SplitAfter(string(data), "\n")
+	if !strings.Contains(lines[1], `"level":"notice","module":"audit","caller":"audit_test.go:23","msg":"grant admin","fields":{"user":"ann"}`) {
+		t.Errorf("Unexpected record %q", lines[1])
+	}
+
+	otherKey := ed25519.NewKeyFromSeed(bytes.Repeat([]byte{8}, ed25519.SeedSize))
+	for name, test := range map[string]struct {
+		log  string
+		pub  ed25519.PublicKey
+		want error
+	}{
+		"modified":  {strings.Replace(string(data), "grant admin", "grant guest", 1), pub, ErrAuditTampered},
+		"removed":   {lines[0] + strings.Join(lines[2:], ""), pub, ErrAuditTampered},
+		"truncated": {strings.Join(lines[:6], ""), pub, ErrAuditTruncated},
+		"partial":   {string(data[:len(data)-10]), pub, ErrAuditTruncated},
+		"other key": {string(data), otherKey.Public().(ed25519.PublicKey), ErrAuditTampered},
+	} {
+		if _, err := VerifyAudit(strings.NewReader(test.log), test.pub); !errors.Is(err, test.want) {
+			t.Errorf("%s: Want: %v Have: %v", name, test.want, err)
+		}
+	}
+}
+
+// shortFile writes half of the lines while fail is set, like a file on a full disk
+type shortFile struct {
+	*os.File
+	fail bool
+}
+
+func (f *shortFile) Write(p []byte) (int, error) {
+	if f.fail {
+		n, _ := f.File.Write(p[:len(p)/2])
+		return n, errors.New("no space left on device")
+	}
+	return f.File.Write(p)
+}
+
+func TestAuditSinkWriteFailure(t *testing.T) {
+	key := ed25519.NewKeyFromSeed(bytes.Repeat([]byte{7}, ed25519.SeedSize))
+	path := filepath.Join(t.TempDir(), "audit.log")
+	sink, err := NewAuditSink(&AuditOptions{Path: path, PrivateKey: key, CheckpointEvery: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	file := &shortFile{File: sink.file.(*os.File)}
+	sink.file = file
+
+	if err := sink.Write(&Info{Level: NoticeLevel, Message: "login"}); err != nil {
+		t.Fatal(err)
+	}
+	file.fail = true
+	if err := sink.Write(&Info{Level: NoticeLevel, Message: "lost"}); err == nil {
+		t.Error("Want the failed write reported")
+	}
+	file.fail = false
+	if err := sink.Write(&Info{Level: NoticeLevel, Message: "logout"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := sink.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := VerifyAuditFile(path, key.Public().(ed25519.PublicKey))
+	if err != nil || report.Records != 2 {
+		t.Errorf("Want a valid log of 2 records Have: %+v %v", report, err)
+	}
+}
+
+func TestAuditSinkResume(t *testing.T) {
+	key := ed25519.NewKeyFromSeed(bytes.Repeat([]byte{7}, ed25519.SeedSize))
+	path := filepath.Join(t.TempDir(), "audit.log")
+	writeAudit(t, path, key, "login", "grant admin", "export")
+
+	data, _ := os.ReadFile(path)
+	if err := os.WriteFile(path, bytes.Replace(data, []byte("grant admin"), []byte("grant guest"), 1), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewAuditSink(&AuditOptions{Path: path, PrivateKey: key}); !errors.Is(err, ErrAuditTampered) {
+		t.Errorf("Want: %v Have: %v", ErrAuditTampered, err)
+	}
+
+	otherKey := ed25519.NewKeyFromSeed(bytes.Repeat([]byte{8}, ed25519.SeedSize))
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewAuditSink(&AuditOptions{Path: path, PrivateKey: otherKey}); !errors.Is(err, ErrAuditTampered) {
+		t.Errorf("Want the checkpoints of another key rejected Have: %v", err)
+	}
+}
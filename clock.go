@@ -0,0 +1,57 @@
+// Package golog Simple flexible go logging
+// This file contains the clocks timing records
+package golog
+
+import (
+	"sync"
+	"time"
+)
+
+// DeterministicTime is the time of the clock of deterministic loggers without a clock
+var DeterministicTime = time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// Clock tells the time of records, durations & elapsed times. Set Options.Clock to control
+// the time in tests
+type Clock interface {
+	Now() time.Time
+}
+
+// systemClock is the clock of loggers without Options.Clock
+type systemClock struct{}
+
+// Now returns the current local time
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// ManualClock is a Clock that only moves when it is set or advanced
+type ManualClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewManualClock returns a clock stopped at t
+func NewManualClock(t time.Time) *ManualClock {
+	return &ManualClock{now: t}
+}
+
+// Now returns the time of the clock
+func (c *ManualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Set moves the clock to t
+func (c *ManualClock) Set(t time.Time) {
+	c.mu.Lock()
+	c.now = t
+	c.mu.Unlock()
+}
+
+// Add advances the clock by d
+func (c *ManualClock) Add(d time.Duration) {
+	c.mu.Lock()
+	c.now = c.now.Add(d)
+	c.mu.Unlock()
+}
@@ -0,0 +1,100 @@
+// Command golog-audit creates the keys of golog audit logs and verifies their integrity.
+//
+//	golog-audit keygen -out audit            writes audit.key & audit.pub, hex encoded
+//	golog-audit verify -pub audit.pub FILE   checks the hash chain & signed checkpoints of FILE
+//
+// verify exits with status 1 if a log was modified or truncated, -min-checkpoint detects
+// logs truncated at a checkpoint
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/AndrewDonelson/golog"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+	var err error
+	switch os.Args[1] {
+	case "keygen":
+		err = keygen(os.Args[2:])
+	case "verify":
+		err = verify(os.Args[2:])
+	default:
+		usage()
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "golog-audit:", err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: golog-audit keygen [-out name] | verify -pub file [-min-checkpoint n] log...")
+	os.Exit(2)
+}
+
+// keygen writes a new key pair
+func keygen(args []string) error {
+	fs := flag.NewFlagSet("keygen", flag.ExitOnError)
+	out := fs.String("out", "audit", "name of the key files, name.key & name.pub")
+	_ = fs.Parse(args)
+
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(*out+".key", []byte(hex.EncodeToString(priv)+"\n"), 0o600); err != nil {
+		return err
+	}
+	return os.WriteFile(*out+".pub", []byte(hex.EncodeToString(pub)+"\n"), 0o644)
+}
+
+// verify checks logs with a public key
+func verify(args []string) error {
+	fs := flag.NewFlagSet("verify", flag.ExitOnError)
+	pubFile := fs.String("pub", "", "hex encoded public key of the checkpoints")
+	minCheckpoint := fs.Uint64("min-checkpoint", 0, "sequence of the last checkpoint the logs should reach at least")
+	_ = fs.Parse(args)
+	if *pubFile == "" || fs.NArg() == 0 {
+		usage()
+	}
+
+	data, err := os.ReadFile(*pubFile)
+	if err != nil {
+		return err
+	}
+	pub, err := hex.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil || len(pub) != ed25519.PublicKeySize {
+		return fmt.Errorf("%s is not a hex encoded ed25519 public key", *pubFile)
+	}
+
+	failed := false
+	for _, path := range fs.Args() {
+		report, err := golog.VerifyAuditFile(path, pub)
+		if err == nil && report.LastCheckpoint < *minCheckpoint {
+			err = fmt.Errorf("%w: last checkpoint %d is before %d", golog.ErrAuditTruncated, report.LastCheckpoint, *minCheckpoint)
+		}
+		if err != nil {
+			fmt.Printf("%s: FAILED: %v\n", path, err)
+			failed = true
+			continue
+		}
+		fmt.Printf("%s: OK, %d records, %d checkpoints, last checkpoint %d at %s\n",
+			path, report.Records, report.Checkpoints, report.LastCheckpoint, report.LastSigned.Format("2006-01-02T15:04:05Z07:00"))
+	}
+	if failed {
+		return errors.New("verification failed")
+	}
+	return nil
+}
@@ -0,0 +1,422 @@
+// Package golog Simple flexible go logging
+// This file contains the text & JSON encoders of field values
+package golog
+
+import (
+	"encoding/json"
+	"math"
+	"strconv"
+	"time"
+	"unicode/utf8"
+)
+
+// ObjectMarshaler is implemented by types that can add themselves to an encoder as a set
+// of key/values, so they are logged without reflection (see Object)
+type ObjectMarshaler interface {
+	MarshalLogObject(enc ObjectEncoder) error
+}
+
+// ArrayMarshaler is implemented by types that can append their elements to an encoder,
+// so they are logged without reflection (see Array)
+type ArrayMarshaler interface {
+	MarshalLogArray(enc ArrayEncoder) error
+}
+
+// ObjectEncoder encodes the key/values of an ObjectMarshaler
+type ObjectEncoder interface {
+	AddString(key, value string)
+	AddInt(key string, value int)
+	AddInt64(key string, value int64)
+	AddUint64(key string, value uint64)
+	AddFloat64(key string, value float64)
+	AddBool(key string, value bool)
+	AddDuration(key string, value time.Duration)
+	AddTime(key string, value time.Time)
+	AddObject(key string, value ObjectMarshaler) error
+	AddArray(key string, value ArrayMarshaler) error
+	AddAny(key string, value interface{}) error // uses reflection
+}
+
+// ArrayEncoder encodes the elements of an ArrayMarshaler
+type ArrayEncoder interface {
+	AppendString(value string)
+	AppendInt(value int)
+	AppendInt64(value int64)
+	AppendUint64(value uint64)
+	AppendFloat64(value float64)
+	AppendBool(value bool)
+	AppendDuration(value time.Duration)
+	AppendTime(value time.Time)
+	AppendObject(value ObjectMarshaler) error
+	AppendArray(value ArrayMarshaler) error
+	AppendAny(value interface{}) error // uses reflection
+}
+
+// valueEncoder is implemented by the encoders of this package, which encode the key & the
+// value of a field separately
+type valueEncoder interface {
+	ArrayEncoder
+	key(key string)
+}
+
+// jsonEncoder appends JSON to buf, elements are separated automatically
+type jsonEncoder struct {
+	buf   []byte
+	start int // offset of the encoded value in buf
+}
+
+// sep appends a comma unless the element is the first of an object or array, or a value
+func (e *jsonEncoder) sep() {
+	if n := len(e.buf); n > e.start {
+		switch e.buf[n-1] {
+		case '{', '[', ':':
+		default:
+			e.buf = append(e.buf, ',')
+		}
+	}
+}
+
+func (e *jsonEncoder) key(key string) {
+	e.sep()
+	e.buf = appendJSONString(e.buf, key)
+	e.buf = append(e.buf, ':')
+}
+
+func (e *jsonEncoder) AddString(key, value string) {
+	e.key(key)
+	e.AppendString(value)
+}
+
+func (e *jsonEncoder) AddInt(key string, value int) {
+	e.key(key)
+	e.AppendInt64(int64(value))
+}
+
+func (e *jsonEncoder) AddInt64(key string, value int64) {
+	e.key(key)
+	e.AppendInt64(value)
+}
+
+func (e *jsonEncoder) AddUint64(key string, value uint64) {
+	e.key(key)
+	e.AppendUint64(value)
+}
+
+func (e *jsonEncoder) AddFloat64(key string, value float64) {
+	e.key(key)
+	e.AppendFloat64(value)
+}
+
+func (e *jsonEncoder) AddBool(key string, value bool) {
+	e.key(key)
+	e.AppendBool(value)
+}
+
+func (e *jsonEncoder) AddDuration(key string, value time.Duration) {
+	e.key(key)
+	e.AppendDuration(value)
+}
+
+func (e *jsonEncoder) AddTime(key string, value time.Time) {
+	e.key(key)
+	e.AppendTime(value)
+}
+
+func (e *jsonEncoder) AddObject(key string, value ObjectMarshaler) error {
+	e.key(key)
+	return e.appendObject(value)
+}
+
+func (e *jsonEncoder) AddArray(key string, value ArrayMarshaler) error {
+	e.key(key)
+	return e.appendArray(value)
+}
+
+func (e *jsonEncoder) AddAny(key string, value interface{}) error {
+	e.key(key)
+	return e.appendAny(value)
+}
+
+func (e *jsonEncoder) AppendString(value string) {
+	e.sep()
+	e.buf = appendJSONString(e.buf, value)
+}
+
+func (e *jsonEncoder) AppendInt(value int) {
+	e.AppendInt64(int64(value))
+}
+
+func (e *jsonEncoder) AppendInt64(value int64) {
+	e.sep()
+	e.buf = strconv.AppendInt(e.buf, value, 10)
+}
+
+func (e *jsonEncoder) AppendUint64(value uint64) {
+	e.sep()
+	e.buf = strconv.AppendUint(e.buf, value, 10)
+}
+
+func (e *jsonEncoder) AppendFloat64(value float64) {
+	e.sep()
+	// JSON has no representation of NaN & infinities
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		e.buf = appendJSONString(e.buf, strconv.FormatFloat(value, 'g', -1, 64))
+		return
+	}
+	e.buf = strconv.AppendFloat(e.buf, value, 'g', -1, 64)
+}
+
+func (e *jsonEncoder) AppendBool(value bool) {
+	e.sep()
+	e.buf = strconv.AppendBool(e.buf, value)
+}
+
+func (e *jsonEncoder) AppendDuration(value time.Duration) {
+	e.AppendString(value.String())
+}
+
+func (e *jsonEncoder) AppendTime(value time.Time) {
+	e.sep()
+	e.buf = append(e.buf, '"')
+	e.buf = value.AppendFormat(e.buf, time.RFC3339Nano)
+	e.buf = append(e.buf, '"')
+}
+
+func (e *jsonEncoder) AppendObject(value ObjectMarshaler) error {
+	e.sep()
+	return e.appendObject(value)
+}
+
+func (e *jsonEncoder) AppendArray(value ArrayMarshaler) error {
+	e.sep()
+	return e.appendArray(value)
+}
+
+func (e *jsonEncoder) AppendAny(value interface{}) error {
+	e.sep()
+	return e.appendAny(value)
+}
+
+func (e *jsonEncoder) appendObject(value ObjectMarshaler) error {
+	e.buf = append(e.buf, '{')
+	err := value.MarshalLogObject(e)
+	e.buf = append(e.buf, '}')
+	return err
+}
+
+func (e *jsonEncoder) appendArray(value ArrayMarshaler) error {
+	e.buf = append(e.buf, '[')
+	err := value.MarshalLogArray(e)
+	e.buf = append(e.buf, ']')
+	return err
+}
+
+func (e *jsonEncoder) appendAny(value interface{}) error {
+	b, err := json.Marshal(value)
+	if err != nil {
+		e.buf = appendJSONString(e.buf, err.Error())
+		return err
+	}
+	e.buf = append(e.buf, b...)
+	return nil
+}
+
+// textEncoder appends logfmt style key=value text to buf. Objects & arrays are enclosed in
+// braces & brackets, strings are quoted when they contain spaces, quotes or '='
+type textEncoder struct {
+	buf   []byte
+	first bool // the next element is the first of the encoded value, an object or an array
+}
+
+// sep appends a space unless the element is the first of an object or array. The last byte
+// of buf can't tell, it may end a string value like "a{"
+func (e *textEncoder) sep() {
+	if e.first {
+		e.first = false
+		return
+	}
+	e.buf = append(e.buf, ' ')
+}
+
+func (e *textEncoder) key(key string) {
+	e.sep()
+	e.buf = append(e.buf, key...)
+	e.buf = append(e.buf, '=')
+	e.first = true // the value follows the '=' without a space
+}
+
+func (e *textEncoder) AddString(key, value string) {
+	e.key(key)
+	e.AppendString(value)
+}
+
+func (e *textEncoder) AddInt(key string, value int) {
+	e.key(key)
+	e.AppendInt64(int64(value))
+}
+
+func (e *textEncoder) AddInt64(key string, value int64) {
+	e.key(key)
+	e.AppendInt64(value)
+}
+
+func (e *textEncoder) AddUint64(key string, value uint64) {
+	e.key(key)
+	e.AppendUint64(value)
+}
+
+func (e *textEncoder) AddFloat64(key string, value float64) {
+	e.key(key)
+	e.AppendFloat64(value)
+}
+
+func (e *textEncoder) AddBool(key string, value bool) {
+	e.key(key)
+	e.AppendBool(value)
+}
+
+func (e *textEncoder) AddDuration(key string, value time.Duration) {
+	e.key(key)
+	e.AppendDuration(value)
+}
+
+func (e *textEncoder) AddTime(key string, value time.Time) {
+	e.key(key)
+	e.AppendTime(value)
+}
+
+func (e *textEncoder) AddObject(key string, value ObjectMarshaler) error {
+	e.key(key)
+	return e.AppendObject(value)
+}
+
+func (e *textEncoder) AddArray(key string, value ArrayMarshaler) error {
+	e.key(key)
+	return e.AppendArray(value)
+}
+
+func (e *textEncoder) AddAny(key string, value interface{}) error {
+	e.key(key)
+	return e.AppendAny(value)
+}
+
+func (e *textEncoder) AppendString(value string) {
+	e.sep()
+	e.buf = appendTextString(e.buf, value)
+}
+
+func (e *textEncoder) AppendInt(value int) {
+	e.AppendInt64(int64(value))
+}
+
+func (e *textEncoder) AppendInt64(value int64) {
+	e.sep()
+	e.buf = strconv.AppendInt(e.buf, value, 10)
+}
+
+func (e *textEncoder) AppendUint64(value uint64) {
+	e.sep()
+	e.buf = strconv.AppendUint(e.buf, value, 10)
+}
+
+func (e *textEncoder) AppendFloat64(value float64) {
+	e.sep()
+	e.buf = strconv.AppendFloat(e.buf, value, 'g', -1, 64)
+}
+
+func (e *textEncoder) AppendBool(value bool) {
+	e.sep()
+	e.buf = strconv.AppendBool(e.buf, value)
+}
+
+func (e *textEncoder) AppendDuration(value time.Duration) {
+	e.sep()
+	e.buf = append(e.buf, value.String()...)
+}
+
+func (e *textEncoder) AppendTime(value time.Time) {
+	e.sep()
+	e.buf = value.AppendFormat(e.buf, time.RFC3339Nano)
+}
+
+func (e *textEncoder) AppendObject(value ObjectMarshaler) error {
+	e.sep()
+	return e.appendObject(value)
+}
+
+func (e *textEncoder) AppendArray(value ArrayMarshaler) error {
+	e.sep()
+	return e.appendArray(value)
+}
+
+func (e *textEncoder) AppendAny(value interface{}) error {
+	e.sep()
+	e.buf = appendTextString(e.buf, string(appendAny(nil, value)))
+	return nil
+}
+
+func (e *textEncoder) appendObject(value ObjectMarshaler) error {
+	e.buf = append(e.buf, '{')
+	e.first = true
+	err := value.MarshalLogObject(e)
+	e.buf = append(e.buf, '}')
+	e.first = false
+	return err
+}
+
+func (e *textEncoder) appendArray(value ArrayMarshaler) error {
+	e.buf = append(e.buf, '[')
+	e.first = true
+	err := value.MarshalLogArray(e)
+	e.buf = append(e.buf, ']')
+	e.first = false
+	return err
+}
+
+// appendTextString appends s, quoted if it is empty or would break key=value parsing
+func appendTextString(buf []byte, s string) []byte {
+	if s == "" {
+		return append(buf, `""`...)
+	}
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; c <= ' ' || c == '=' || c == '"' || c == '\\' || c == 0x7f {
+			return strconv.AppendQuote(buf, s)
+		}
+	}
+	return append(buf, s...)
+}
+
+// appendJSONString appends s as a quoted & escaped JSON string
+func appendJSONString(buf []byte, s string) []byte {
+	const hex = "0123456789abcdef"
+	buf = append(buf, '"')
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c < utf8.RuneSelf {
+			switch {
+			case c == '"' || c == '\\':
+				buf = append(buf, '\\', c)
+			case c == '\n':
+				buf = append(buf, '\\', 'n')
+			case c == '\r':
+				buf = append(buf, '\\', 'r')
+			case c == '\t':
+				buf = append(buf, '\\', 't')
+			case c < ' ':
+				buf = append(buf, '\\', 'u', '0', '0', hex[c>>4], hex[c&0xf])
+			default:
+				buf = append(buf, c)
+			}
+			i++
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			buf = append(buf, "\ufffd"...)
+		} else {
+			buf = append(buf, s[i:i+size]...)
+		}
+		i += size
+	}
+	return append(buf, '"')
+}
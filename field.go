@@ -0,0 +1,370 @@
+// Package golog Simple flexible go logging
+// This file contains the code for key/value fields attached to records
+package golog
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// fieldKind tells which member of a Field holds its value
+type fieldKind uint8
+
+const (
+	anyField      fieldKind = iota // Value, encoded with reflection
+	stringField                    // str
+	intField                       // num
+	uintField                      // num
+	floatField                     // num, as float64 bits
+	boolField                      // num, 1 for true
+	durationField                  // num, nanoseconds
+	timeField                      // num, unix nanoseconds, Value holds the *time.Location
+	errorField                     // Value
+	objectField                    // Value, an ObjectMarshaler
+	arrayField                     // Value, an ArrayMarshaler
+)
+
+// Field is a key/value pair attached to every record of a logger. Fields created with the
+// typed constructors (String, Int, ...) hold their value without boxing it in an interface
+// and are encoded without reflection
+type Field struct {
+	Key   string
+	Value interface{} // value of fields created by Any, WithField(s) or as a literal
+	kind  fieldKind
+	num   int64
+	str   string
+}
+
+// String returns a field holding a string
+func String(key, value string) Field {
+	return Field{Key: key, kind: stringField, str: value}
+}
+
+// Int returns a field holding an int
+func Int(key string, value int) Field {
+	return Field{Key: key, kind: intField, num: int64(value)}
+}
+
+// Int64 returns a field holding an int64
+func Int64(key string, value int64) Field {
+	return Field{Key: key, kind: intField, num: value}
+}
+
+// Uint64 returns a field holding an uint64
+func Uint64(key string, value uint64) Field {
+	return Field{Key: key, kind: uintField, num: int64(value)}
+}
+
+// Float64 returns a field holding a float64
+func Float64(key string, value float64) Field {
+	return Field{Key: key, kind: floatField, num: int64(math.Float64bits(value))}
+}
+
+// Bool returns a field holding a bool
+func Bool(key string, value bool) Field {
+	f := Field{Key: key, kind: boolField}
+	if value {
+		f.num = 1
+	}
+	return f
+}
+
+// Duration returns a field holding a time.Duration
+func Duration(key string, value time.Duration) Field {
+	return Field{Key: key, kind: durationField, num: int64(value)}
+}
+
+// Time returns a field holding a time.Time
+func Time(key string, value time.Time) Field {
+	return Field{Key: key, kind: timeField, num: value.UnixNano(), Value: value.Location()}
+}
+
+// Err returns a field holding an error under the key "error"
+func Err(err error) Field {
+	return NamedErr("error", err)
+}
+
+// NamedErr returns a field holding an error
+func NamedErr(key string, err error) Field {
+	return Field{Key: key, kind: errorField, Value: err}
+}
+
+// Object returns a field holding a value that encodes itself as key/values
+func Object(key string, value ObjectMarshaler) Field {
+	return Field{Key: key, kind: objectField, Value: value}
+}
+
+// Array returns a field holding a value that encodes itself as a list
+func Array(key string, value ArrayMarshaler) Field {
+	return Field{Key: key, kind: arrayField, Value: value}
+}
+
+// Any returns a field using the typed representation of the value when it has one,
+// other values are encoded with reflection
+func Any(key string, value interface{}) Field {
+	switch v := value.(type) {
+	case string:
+		return String(key, v)
+	case int:
+		return Int(key, v)
+	case int64:
+		return Int64(key, v)
+	case uint64:
+		return Uint64(key, v)
+	case float64:
+		return Float64(key, v)
+	case bool:
+		return Bool(key, v)
+	case time.Duration:
+		return Duration(key, v)
+	case time.Time:
+		return Time(key, v)
+	case error:
+		return NamedErr(key, v)
+	case ObjectMarshaler:
+		return Object(key, v)
+	case ArrayMarshaler:
+		return Array(key, v)
+	}
+	return Field{Key: key, Value: value}
+}
+
+// Interface returns the value of the field
+func (f Field) Interface() interface{} {
+	switch f.kind {
+	case stringField:
+		return f.str
+	case intField:
+		return f.num
+	case uintField:
+		return uint64(f.num)
+	case floatField:
+		return math.Float64frombits(uint64(f.num))
+	case boolField:
+		return f.num == 1
+	case durationField:
+		return time.Duration(f.num)
+	case timeField:
+		return f.time()
+	}
+	return f.Value
+}
+
+// time returns the value of a timeField
+func (f *Field) time() time.Time {
+	t := time.Unix(0, f.num)
+	if loc, ok := f.Value.(*time.Location); ok && loc != nil {
+		t = t.In(loc)
+	}
+	return t
+}
+
+// encode adds the field to an encoder. The encoders of this package take the value from
+// encodeValue, others get the value returned by Interface
+func (f *Field) encode(enc ObjectEncoder) error {
+	if ve, ok := enc.(valueEncoder); ok {
+		ve.key(f.Key)
+		return f.encodeValue(ve)
+	}
+	return enc.AddAny(f.Key, f.Interface())
+}
+
+// encodeValue appends the value of the field to an encoder, without its key
+func (f *Field) encodeValue(enc ArrayEncoder) error {
+	switch f.kind {
+	case stringField:
+		enc.AppendString(f.str)
+	case intField:
+		enc.AppendInt64(f.num)
+	case uintField:
+		enc.AppendUint64(uint64(f.num))
+	case floatField:
+		enc.AppendFloat64(math.Float64frombits(uint64(f.num)))
+	case boolField:
+		enc.AppendBool(f.num == 1)
+	case durationField:
+		enc.AppendDuration(time.Duration(f.num))
+	case timeField:
+		enc.AppendTime(f.time())
+	case errorField:
+		if err, ok := f.Value.(error); ok && err != nil {
+			enc.AppendString(err.Error())
+		} else {
+			enc.AppendString("<nil>")
+		}
+	case objectField:
+		return enc.AppendObject(f.Value.(ObjectMarshaler))
+	case arrayField:
+		return enc.AppendArray(f.Value.(ArrayMarshaler))
+	default:
+		return enc.AppendAny(f.Value)
+	}
+	return nil
+}
+
+// appendTo appends the plain text value of the field to buf, strings are not quoted
+func (f *Field) appendTo(buf []byte) []byte {
+	switch f.kind {
+	case stringField:
+		return append(buf, f.str...)
+	case anyField:
+		if f.Value == nil {
+			return append(buf, "<nil>"...)
+		}
+		return appendAny(buf, f.Value)
+	}
+	enc := textEncoder{buf: buf, first: true}
+	f.appendText(&enc)
+	return enc.buf
+}
+
+// appendText appends the value of the field to a text encoder. Taking the concrete encoder
+// rather than an ArrayEncoder keeps it on the stack, objects & arrays are given to their
+// marshaler through a copy
+func (f *Field) appendText(enc *textEncoder) {
+	switch f.kind {
+	case stringField:
+		enc.AppendString(f.str)
+	case intField:
+		enc.AppendInt64(f.num)
+	case uintField:
+		enc.AppendUint64(uint64(f.num))
+	case floatField:
+		enc.AppendFloat64(math.Float64frombits(uint64(f.num)))
+	case boolField:
+		enc.AppendBool(f.num == 1)
+	case durationField:
+		enc.AppendDuration(time.Duration(f.num))
+	case timeField:
+		enc.AppendTime(f.time())
+	case errorField:
+		if err, ok := f.Value.(error); ok && err != nil {
+			enc.AppendString(err.Error())
+		} else {
+			enc.AppendString("<nil>")
+		}
+	case objectField, arrayField:
+		enc.sep()
+		m := textEncoder{buf: enc.buf, first: true}
+		_ = f.encodeValue(&m)
+		enc.buf = m.buf
+	default:
+		_ = enc.AppendAny(f.Value)
+	}
+}
+
+// appendJSON appends the JSON value of the field to buf
+func (f *Field) appendJSON(buf []byte) []byte {
+	enc := jsonEncoder{buf: buf, start: len(buf)}
+	_ = f.encodeValue(&enc)
+	return enc.buf
+}
+
+// appendFieldsText appends the fields as logfmt style key=value pairs
+func appendFieldsText(buf []byte, fields []Field) []byte {
+	enc := textEncoder{buf: buf, first: true}
+	for i := range fields {
+		enc.key(fields[i].Key)
+		fields[i].appendText(&enc)
+	}
+	return enc.buf
+}
+
+// appendFieldsJSON appends the fields as a JSON object
+func appendFieldsJSON(buf []byte, fields []Field) []byte {
+	enc := jsonEncoder{buf: append(buf, '{'), start: len(buf)}
+	for i := range fields {
+		_ = fields[i].encode(&enc)
+	}
+	return append(enc.buf, '}')
+}
+
+// With returns a logger sharing this logger's output whose records carry the fields in
+// addition to the fields of this logger. Fields with the same key are replaced
+func (l *Logger) With(fields ...Field) *Logger {
+	c := *l
+	c.fields = make([]Field, 0, len(l.fields)+len(fields))
+	for _, f := range l.fields {
+		replaced := false
+		for i := range fields {
+			if fields[i].Key == f.Key {
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			c.fields = append(c.fields, f)
+		}
+	}
+	c.fields = append(c.fields, fields...)
+	return &c
+}
+
+// splitFields separates the trailing Field arguments of a log call from the message arguments
+func splitFields(a []interface{}) ([]interface{}, []Field) {
+	i := len(a)
+	for i > 0 {
+		if _, ok := a[i-1].(Field); !ok {
+			break
+		}
+		i--
+	}
+	if i == len(a) {
+		return a, nil
+	}
+	fields := make([]Field, 0, len(a)-i)
+	for _, f := range a[i:] {
+		fields = append(fields, f.(Field))
+	}
+	return a[:i], fields
+}
+
+// withArgFields returns the message arguments of a log call and the logger writing the
+// record, which carries the trailing Field arguments if there are any
+func (l *Logger) withArgFields(a []interface{}) ([]interface{}, *Logger) {
+	a, fields := splitFields(a)
+	if fields == nil {
+		return a, l
+	}
+	return a, l.With(fields...)
+}
+
+// WithField returns a logger sharing this logger's output whose records carry the key/value
+// in addition to the fields of this logger. A field with the same key is replaced
+func (l *Logger) WithField(key string, value interface{}) *Logger {
+	return l.With(Any(key, value))
+}
+
+// WithFields is like WithField for several key/values, which are added in key order
+func (l *Logger) WithFields(fields map[string]interface{}) *Logger {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	list := make([]Field, 0, len(keys))
+	for _, k := range keys {
+		list = append(list, Any(k, fields[k]))
+	}
+	return l.With(list...)
+}
+
+// field returns the field with the given key, the last one if several have it
+func (r *Info) field(key string) *Field {
+	for i := len(r.Fields) - 1; i >= 0; i-- {
+		if r.Fields[i].Key == key {
+			return &r.Fields[i]
+		}
+	}
+	return nil
+}
+
+// Field returns the value of the field with the given key
+func (r *Info) Field(key string) (interface{}, bool) {
+	if f := r.field(key); f != nil {
+		return f.Interface(), true
+	}
+	return nil, false
+}
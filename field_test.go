@@ -0,0 +1,164 @@
+package golog
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"testing"
+	"time"
+)
+
+type testUser struct {
+	Name  string
+	Age   int
+	Roles testRoles
+}
+
+func (u testUser) MarshalLogObject(enc ObjectEncoder) error {
+	enc.AddString("name", u.Name)
+	enc.AddInt("age", u.Age)
+	return enc.AddArray("roles", u.Roles)
+}
+
+type testRoles []string
+
+func (r testRoles) MarshalLogArray(enc ArrayEncoder) error {
+	for _, role := range r {
+		enc.AppendString(role)
+	}
+	return nil
+}
+
+func testFields() []Field {
+	at := time.Date(2023, 4, 29, 7, 33, 37, 0, time.UTC)
+	return []Field{
+		String("s", "two words"),
+		Int("i", -3),
+		Uint64("u", 7),
+		Float64("f", 1.5),
+		Bool("b", true),
+		Duration("d", 1500*time.Millisecond),
+		Time("t", at),
+		Err(errors.New(`bad "input"`)),
+		Object("user", testUser{Name: "alice", Age: 30, Roles: testRoles{"admin", "dev"}}),
+		Any("any", map[string]int{"k": 1}),
+	}
+}
+
+func TestFieldsText(t *testing.T) {
+	want := `s="two words" i=-3 u=7 f=1.5 b=true d=1.5s t=2023-04-29T07:33:37Z ` +
+		`error="bad \"input\"" user={name=alice age=30 roles=[admin dev]} any=map[k:1]`
+	if have := string(appendFieldsText(nil, testFields())); have != want {
+		t.Errorf("\nWant: %s\nHave: %s", want, have)
+	}
+}
+
+func TestFieldsJSON(t *testing.T) {
+	want := `{"s":"two words","i":-3,"u":7,"f":1.5,"b":true,"d":"1.5s","t":"2023-04-29T07:33:37Z",` +
+		`"error":"bad \"input\"","user":{"name":"alice","age":30,"roles":["admin","dev"]},"any":{"k":1}}`
+	have := appendFieldsJSON(nil, testFields())
+	if string(have) != want {
+		t.Errorf("\nWant: %s\nHave: %s", want, have)
+	}
+	if !json.Valid(have) {
+		t.Errorf("Invalid JSON: %s", have)
+	}
+
+	nan := appendFieldsJSON(nil, []Field{Float64("nan", math.NaN())})
+	if !json.Valid(nan) {
+		t.Errorf("Invalid JSON: %s", nan)
+	}
+}
+
+func TestAnyField(t *testing.T) {
+	var tests = []struct {
+		value interface{}
+		kind  fieldKind
+	}{
+		{"s", stringField},
+		{1, intField},
+		{int64(1), intField},
+		{uint64(1), uintField},
+		{1.0, floatField},
+		{true, boolField},
+		{time.Second, durationField},
+		{time.Now(), timeField},
+		{errors.New("e"), errorField},
+		{testUser{}, objectField},
+		{testRoles{}, arrayField},
+		{struct{}{}, anyField},
+	}
+
+	for _, test := range tests {
+		f := Any("k", test.value)
+		if f.kind != test.kind {
+			t.Errorf("%T: Want kind %d Have %d", test.value, test.kind, f.kind)
+		}
+	}
+
+	if have := Any("k", 1).Interface(); have != int64(1) {
+		t.Errorf("Want int64 1 Have %T %v", have, have)
+	}
+	if have := Any("k", 1.5).Interface(); have != 1.5 {
+		t.Errorf("Want 1.5 Have %v", have)
+	}
+
+	at := time.Date(2023, 4, 29, 7, 33, 37, 5, time.FixedZone("X", 3600))
+	if have := Time("t", at).Interface().(time.Time); !have.Equal(at) || have.Location() != at.Location() {
+		t.Errorf("Want %v Have %v", at, have)
+	}
+}
+
+func TestJSONString(t *testing.T) {
+	want := `"a\"b\\c\n\t\u0001é` + "�" + `"`
+	if have := string(appendJSONString(nil, "a\"b\\c\n\t\x01é\xff")); have != want {
+		t.Errorf("\nWant: %s\nHave: %s", want, have)
+	}
+}
+
+func TestFieldsTextBraces(t *testing.T) {
+	fields := []Field{
+		String("k", "a{"),
+		Int("n", 2),
+		Object("user", testUser{Name: "[x", Roles: testRoles{"a{", "b"}}),
+		Array("empty", testRoles{}),
+		String("s", "}"),
+	}
+	want := `k=a{ n=2 user={name=[x age=0 roles=[a{ b]} empty=[] s=}`
+	if have := string(appendFieldsText(nil, fields)); have != want {
+		t.Errorf("\nWant: %s\nHave: %s", want, have)
+	}
+}
+
+func TestFieldArguments(t *testing.T) {
+	var buf bytes.Buffer
+	log := NewLogger(&Options{Module: "fieldargs", Out: &buf, IDs: IDPerLogger})
+	log.SetEnvironment(EnvDevelopment)
+	_ = log.SetFormat("%{message} %{fields}")
+	log = log.With(String("user", "alice"), Int("n", 1))
+
+	log.Info("msg", Int("n", 2), Bool("ok", true))
+	log.V(0).Info("verbose", Int("n", 3))
+	log.Info("plain")
+	want := "msg user=alice n=2 ok=true\n" +
+		"verbose user=alice n=3\n" +
+		"plain user=alice n=1\n"
+	if have := buf.String(); have != want {
+		t.Errorf("\nWant: %q\nHave: %q", want, have)
+	}
+}
+
+func BenchmarkLoggerFieldsJSON(b *testing.B) {
+	log := NewLogger(&Options{Module: "BenchFieldsJSON", Out: io.Discard})
+	log.SetEnvironment(EnvDevelopment)
+	_ = log.SetFormat(`{"msg":%{message:json},"fields":%{fields:json}}`)
+	log = log.With(String("user", "alice"), Int("n", 42), Duration("took", time.Second),
+		Object("obj", testUser{Name: "alice", Roles: testRoles{"admin"}}))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		log.Info("Info Logging")
+	}
+}
@@ -0,0 +1,155 @@
+// Package golog Simple flexible go logging
+// This file contains the flight recorder keeping the last records in memory
+package golog
+
+import (
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+)
+
+// flightEntry is a record kept by a FlightRecorder
+type flightEntry struct {
+	info    Info
+	written bool // the record was written to the output
+}
+
+// FlightRecorder keeps the last records of a logger in memory, at every level up to Debug
+// and V(n) records up to its verbosity, whatever the level of the logger. When an Error is
+// written the kept records that were not written are dumped to the output before it, giving
+// the debug context of production failures without writing Debug records all the time.
+// Dump & ServeHTTP write them on demand
+type FlightRecorder struct {
+	mu      sync.Mutex
+	entries []flightEntry          // ring of the last records
+	n       uint64                 // records kept so far, entries[n%len(entries)] is the next slot
+	dumped  uint64                 // records before it were dumped after an error
+	verbose atomic.Int32           // highest V(n) kept
+	w       atomic.Pointer[Worker] // worker of the logger recording to it, renders the records
+}
+
+// NewFlightRecorder returns a recorder keeping the last size records, 1000 if size <= 0, and
+// V(1) records like the development environment writes. Attach it with Options.FlightRecorder
+// or Logger.SetFlightRecorder
+func NewFlightRecorder(size int) *FlightRecorder {
+	if size <= 0 {
+		size = 1000
+	}
+	r := &FlightRecorder{entries: make([]flightEntry, size)}
+	r.verbose.Store(1)
+	return r
+}
+
+// SetVerbosity sets the highest V(n) kept, 0 keeps none
+func (r *FlightRecorder) SetVerbosity(v int) {
+	r.verbose.Store(int32(v))
+}
+
+// SetFlightRecorder keeps the last records of the logger, and of the loggers sharing its
+// worker, in r. nil stops recording
+func (l *Logger) SetFlightRecorder(r *FlightRecorder) {
+	if r != nil {
+		r.w.Store(l.worker)
+	}
+	l.worker.update(func(c *workerConfig) {
+		c.recorder = r
+	})
+}
+
+// allows reports if records of the verbosity are kept
+func (r *FlightRecorder) allows(v int) bool {
+	return r != nil && v <= int(r.verbose.Load())
+}
+
+// keep copies a record into the ring
+func (r *FlightRecorder) keep(info *Info, written bool) {
+	if !r.allows(info.Verbosity) {
+		return
+	}
+	r.mu.Lock()
+	e := &r.entries[r.n%uint64(len(r.entries))]
+	e.info, e.written = *info, written
+	r.n++
+	r.mu.Unlock()
+}
+
+// records returns copies of the kept records from the seq-th one, or from the oldest
+func (r *FlightRecorder) records(seq uint64, unwritten bool) []Info {
+	if size := uint64(len(r.entries)); r.n > size && seq < r.n-size {
+		seq = r.n - size
+	}
+	var infos []Info
+	for ; seq < r.n; seq++ {
+		if e := &r.entries[seq%uint64(len(r.entries))]; !unwritten || !e.written {
+			infos = append(infos, e.info)
+		}
+	}
+	return infos
+}
+
+// unwritten returns the kept records that were not written nor dumped yet
+func (r *FlightRecorder) unwritten() []Info {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	infos := r.records(r.dumped, true)
+	r.dumped = r.n
+	return infos
+}
+
+// all returns all kept records
+func (r *FlightRecorder) all() []Info {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.records(0, false)
+}
+
+// Dump writes all kept records to the output of the logger, oldest first
+func (r *FlightRecorder) Dump() {
+	if w := r.w.Load(); w != nil {
+		w.dumpRecords(2, w.config(), r.all())
+	}
+}
+
+// WriteTo writes all kept records in the format of the logger, without colors, oldest first
+func (r *FlightRecorder) WriteTo(out io.Writer) (int64, error) {
+	w := r.w.Load()
+	if w == nil {
+		return 0, nil
+	}
+	var buf []byte
+	c := w.config()
+	for _, info := range r.all() {
+		info.resolveCaller()
+		buf = c.render(buf, &info, ClrDisabled)
+		if len(buf) == 0 || buf[len(buf)-1] != '\n' {
+			buf = append(buf, '\n')
+		}
+	}
+	n, err := out.Write(buf)
+	return int64(n), err
+}
+
+// ServeHTTP writes all kept records as text, oldest first
+func (r *FlightRecorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_, _ = r.WriteTo(w)
+}
+
+// dumpRecords writes records kept by the flight recorder to the output
+func (w *Worker) dumpRecords(calldepth int, c *workerConfig, infos []Info) {
+	if len(infos) == 0 {
+		return
+	}
+	bp := bufPool.Get().(*[]byte)
+	for i := range infos {
+		infos[i].resolveCaller()
+		buf := c.render((*bp)[:0], &infos[i], c.color)
+		if err := w.output(calldepth+1, buf); err != nil {
+			w.fail(c, OutputSink, err)
+			w.errors.fallback(c, buf)
+		}
+		*bp = buf
+	}
+	bufPool.Put(bp)
+}
@@ -0,0 +1,77 @@
+package golog
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func TestFlightRecorder(t *testing.T) {
+	var buf bytes.Buffer
+	fr := NewFlightRecorder(4)
+	l := NewLogger(&Options{Module: "flight", Out: &buf, Levels: LevelOverrides{}, IDs: IDPerLogger, FlightRecorder: fr})
+	l.SetEnvironment(EnvProduction)
+	_ = l.SetFormat("%{level} %{file}:%{line} %{message}")
+
+	if !l.Enabled(DebugLevel) {
+		t.Error("Want Debug enabled for the flight recorder")
+	}
+	for i := 1; i <= 4; i++ {
+		l.Debug("step " + strconv.Itoa(i))
+	}
+	l.V(1).Info("verbose step")
+	l.V(2).Info("too verbose to be kept")
+	l.Warning("retrying")
+	l.Error("failed")
+	l.Error("failed again, nothing new to dump")
+
+	want := "WARNING flight_test.go:25 retrying\n" +
+		"DEBUG flight_test.go:21 step 3\n" +
+		"DEBUG flight_test.go:21 step 4\n" +
+		"DEBUG flight_test.go:23 verbose step\n" +
+		"ERROR flight_test.go:26 failed\n" +
+		"ERROR flight_test.go:27 failed again, nothing new to dump\n"
+	if have := buf.String(); have != want {
+		t.Errorf("\nWant: %q\nHave: %q", want, have)
+	}
+
+	rec := httptest.NewRecorder()
+	fr.ServeHTTP(rec, httptest.NewRequest("GET", "/debug/records", nil))
+	want = "DEBUG flight_test.go:23 verbose step\n" +
+		"WARNING flight_test.go:25 retrying\n" +
+		"ERROR flight_test.go:26 failed\n" +
+		"ERROR flight_test.go:27 failed again, nothing new to dump\n"
+	if have := rec.Body.String(); have != want {
+		t.Errorf("\nWant: %q\nHave: %q", want, have)
+	}
+
+	buf.Reset()
+	fr.Dump()
+	if have := buf.String(); have != want {
+		t.Errorf("\nWant: %q\nHave: %q", want, have)
+	}
+}
+
+func TestFlightRecorderVerbosity(t *testing.T) {
+	var buf bytes.Buffer
+	fr := NewFlightRecorder(10)
+	fr.SetVerbosity(3)
+	l := NewLogger(&Options{Module: "flight", Out: &buf, Levels: LevelOverrides{}, IDs: IDPerLogger, FlightRecorder: fr})
+	l.SetEnvironment(EnvProduction)
+	_ = l.SetFormat("%{message}")
+
+	for v := 1; v <= 4; v++ {
+		l.V(v).Info("v" + strconv.Itoa(v))
+	}
+	fr.SetVerbosity(0)
+	l.V(1).Info("not kept")
+
+	var out bytes.Buffer
+	if _, err := fr.WriteTo(&out); err != nil {
+		t.Fatal(err)
+	}
+	if want, have := "v1\nv2\nv3\n", out.String(); have != want {
+		t.Errorf("\nWant: %q\nHave: %q", want, have)
+	}
+}
@@ -0,0 +1,84 @@
+package golog
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+var update = flag.Bool("update", false, "update the golden files of testdata")
+
+// logGolden logs the records of the golden files with a deterministic logger
+func logGolden(format func(*Logger)) []byte {
+	var buf bytes.Buffer
+	clock := NewManualClock(DeterministicTime)
+	log := NewLogger(&Options{Module: "golden", Out: &buf, Deterministic: true, Clock: clock, Levels: LevelOverrides{}})
+	log.SetEnvironment(EnvDevelopment)
+	format(log)
+
+	log.Info("service started")
+	clock.Add(1500 * time.Millisecond)
+	log.With(String("user", "alice"), Int("attempt", 2), Bool("admin", false)).Warning(`password "expired"`)
+	clock.Add(250 * time.Millisecond)
+	log.With(Err(errors.New("connection refused")), Duration("after", 3*time.Second)).Error("payment failed\nretrying")
+	log.Debugf("%d items in cache", 42)
+	return buf.Bytes()
+}
+
+func TestGolden(t *testing.T) {
+	for _, test := range []struct {
+		name   string
+		format func(*Logger)
+	}{
+		{"text", func(l *Logger) {
+			_ = l.SetFormat("%{id:06} %{time} %{level:-7} [%{module}] %{file}:%{line} %{message} %{fields}")
+		}},
+		{"printf", func(l *Logger) {
+			l.worker.update(func(c *workerConfig) { c.setPrintfFormat(FmtDevelopmentLog) })
+		}},
+		{"json", func(l *Logger) { _ = l.SetFormat(FmtJSON) }},
+		{"logfmt", func(l *Logger) { _ = l.SetFormat(FmtLogfmt) }},
+		{"pretty", func(l *Logger) { l.UsePrettyConsole(nil) }},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			have := logGolden(test.format)
+			if again := logGolden(test.format); !bytes.Equal(have, again) {
+				t.Fatalf("Output is not deterministic\nFirst:  %q\nSecond: %q", have, again)
+			}
+
+			path := filepath.Join("testdata", test.name+".golden")
+			if *update {
+				if err := os.WriteFile(path, have, 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(have, want) {
+				t.Errorf("Output differs from %s (run go test -update)\nWant: %s\nHave: %s", path, want, have)
+			}
+		})
+	}
+}
+
+func TestResetIDs(t *testing.T) {
+	var buf bytes.Buffer
+	log := NewLogger(&Options{Module: "resetids", Out: &buf, IDs: IDPerLogger, Levels: LevelOverrides{}})
+	log.SetEnvironment(EnvDevelopment)
+	_ = log.SetFormat("%{id} %{message}")
+
+	derived := log.WithField("k", "v")
+	log.Info("a")
+	derived.Info("b")
+	log.ResetIDs()
+	derived.Info("c")
+	if have := buf.String(); have != "1 a\n2 b\n1 c\n" {
+		t.Errorf("Want: %q Have: %q", "1 a\n2 b\n1 c\n", have)
+	}
+}
@@ -4,14 +4,15 @@ package golog
 
 // Import packages
 import (
+	"bufio"
+	"context"
 	"flag"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"os"
-	"path"
-	"strings"
-	"sync/atomic"
+	"runtime"
 	"time"
 )
 
@@ -31,6 +32,8 @@ const (
 	// "%{method}":     "%[10]s",
 	// "%{statuscode}": "%[11]d",
 	// "%{route}":      "%[12]s",
+	// "%{traceid}":    "%[13]s",
+	// "%{spanid}":     "%[14]s",
 
 	// FmtProductionLog is the built-in production log format
 	// [000001] [gwfnode] RAW 2023-04-29 07:33:37 golog.go#232 : gwfnode Server [Version 2023.04.28f1.0] (EnvProduction)
@@ -43,6 +46,12 @@ const (
 	// [000001] [gwfnode] RAW 2023-04-29 07:33:37 golog.go#232-github.com/NlaakStudiosLLC/io.gwf/sdk/pkgs/util.SetGoLogBuildEnv : gwfnode Server [Version 2023.04.28f1.0] (EnvDevelopment)
 	FmtDevelopmentLog = "[%.6[1]d] [%.16[3]s] %.4[7]s %.19[2]s %[5]s#%[6]d-%[4]s : %[8]s"
 
+	// FmtJSON is a format writing records as JSON objects
+	FmtJSON = `{"time":"%{time:2006-01-02T15:04:05.000Z07:00}","id":%{id:json},"level":%{level:lower:json},"module":%{module:json},"caller":"%{file}:%{line}","msg":%{message:json},"fields":%{fields:json}}`
+
+	// FmtLogfmt is a format writing records as logfmt key=value pairs
+	FmtLogfmt = `time=%{time:2006-01-02T15:04:05.000Z07:00} id=%{id} level=%{level:lower} module=%{module} caller=%{file}:%{line} msg=%{message:json} %{fields}`
+
 	// FmtDefault is the default log format
 	FmtDefault = FmtProductionLog
 
@@ -97,12 +106,18 @@ const (
 )
 
 // Logger class that is an interface to user to log messages, Module is the module for which we are testing
-// worker is variable of Worker class that is used in bottom layers to log the message
+// worker is variable of Worker class that is used in bottom layers to log the message.
+// Setters may run while other goroutines log, but should not race with each other since
+// they also record the new value in Options
 type Logger struct {
 	Options Options
 	started time.Time // Set once on initialization
 	timer   time.Time // reset on each call to timeElapsed()
 	worker  *Worker
+	clock   Clock
+	ids     *idSequence     // shared with the loggers derived by With & WithContext
+	ctx     context.Context // bound by WithContext, used to correlate records with traces
+	fields  []Field         // attached by WithField(s) to every record
 }
 
 func init() {
@@ -127,9 +142,35 @@ func NewLogger(opts *Options) *Logger {
 		opts.Module = "unknown"
 	}
 
+	if opts.Levels == nil {
+		opts.Levels = levelOverridesFromEnv()
+	}
+
+	if opts.Deterministic {
+		if opts.Clock == nil {
+			opts.Clock = NewManualClock(DeterministicTime)
+		}
+		if opts.IDs == IDGlobal {
+			opts.IDs = IDPerLogger
+		}
+	}
+	if opts.Clock == nil {
+		opts.Clock = systemClock{}
+	}
+
 	newWorker := NewWorker("", 0, opts.UseColor, opts.Out)
-	l := &Logger{worker: newWorker}
+	newWorker.SetLevelOverrides(opts.Levels)
+	_ = newWorker.SetTheme(opts.Theme)
+	newWorker.update(func(c *workerConfig) {
+		if opts.Deterministic {
+			c.term = ColorNone
+		}
+		c.metrics = opts.Metrics
+		c.errHandler, c.fallback = opts.ErrorHandler, opts.Fallback
+	})
+	l := &Logger{worker: newWorker, clock: opts.Clock, ids: newIDSequence(opts.IDs)}
 	l.Options = *opts
+	l.SetFlightRecorder(opts.FlightRecorder)
 	l.init()
 	return l
 }
@@ -146,11 +187,24 @@ func (l *Logger) init() {
 }
 
 func (l *Logger) timeReset() {
-	l.timer = time.Now()
+	l.timer = l.clock.Now()
+}
+
+// SetIDStrategy sets how the ids of the records of the logger are generated, loggers
+// derived from it before the call keep their ids
+func (l *Logger) SetIDStrategy(strategy IDStrategy) {
+	l.Options.IDs = strategy
+	l.ids = newIDSequence(strategy)
+}
+
+// ResetIDs restarts the record ids of the logger at 1. With IDGlobal this restarts the
+// sequence shared by all loggers, other strategies are not sequences and are not reset
+func (l *Logger) ResetIDs() {
+	l.ids.reset()
 }
 
 func (l *Logger) timeElapsed(start time.Time) time.Duration {
-	return time.Since(start)
+	return l.clock.Now().Sub(start)
 }
 
 func (l *Logger) timeLog(name string) {
@@ -159,47 +213,101 @@ func (l *Logger) timeLog(name string) {
 
 // logInternal ...
 func (l *Logger) logInternal(lvl LogLevel, pos int, a ...interface{}) {
-	var (
-		function, filename string
-		line               int
-	)
-
-	function, filename, line = GetCaller(pos)
-
-	msg := fmt.Sprintf("%v", a...)
-	filename = path.Base(filename)
-	info := &Info{
-		ID:       atomic.AddUint64(&logNo, 1),
-		Time:     time.Now().Format(l.worker.timeFormat),
-		Module:   l.Options.Module,
-		Level:    lvl,
-		Message:  msg,
-		Filename: filename,
-		Line:     line,
-		Function: function,
-		Duration: l.timeElapsed(l.timer),
-		//format:   formatString,
+	l.logContext(nil, lvl, pos+1, a...)
+}
+
+// logfInternal is logInternal for a message using the same syntax and options as fmt.Printf,
+// which is only formatted if the level is enabled
+func (l *Logger) logfInternal(lvl LogLevel, pos int, format string, a ...interface{}) {
+	if !l.worker.enabled(lvl, 0, l.Options.Module) {
+		return
+	}
+	l.write(l.newInfo(nil, lvl, pos+1, fmt.Sprintf(format, a...)))
+}
+
+// logFnInternal is logInternal for a message returned by fn, which is only called if the
+// level is enabled
+func (l *Logger) logFnInternal(lvl LogLevel, pos int, fn func() string) {
+	if !l.worker.enabled(lvl, 0, l.Options.Module) {
+		return
+	}
+	l.write(l.newInfo(nil, lvl, pos+1, fn()))
+}
+
+// logContext is logInternal with an optional context carrying the active span
+func (l *Logger) logContext(ctx context.Context, lvl LogLevel, pos int, a ...interface{}) {
+	if !l.worker.enabled(lvl, 0, l.Options.Module) {
+		return
 	}
-	l.worker.Log(lvl, 2, info)
-}
-
-func (l *Logger) traceInternal(pos int, a ...interface{}) {
-	function, file, line := GetCaller(pos)
-	msg := fmt.Sprintf("%v", a...)
-	file = path.Base(file)
-	info := &Info{
-		ID:       atomic.AddUint64(&logNo, 1),
-		Time:     time.Now().Format(l.worker.timeFormat),
-		Module:   l.Options.Module,
-		Level:    TraceLevel,
-		Message:  msg,
-		Filename: file,
-		Line:     line,
-		Function: function,
-		Duration: l.timeElapsed(l.timer),
-		//format:   formatString,
+	a, l = l.withArgFields(a)
+	l.write(l.newInfo(ctx, lvl, pos+1, sprint(a)))
+}
+
+// write hands the record to the worker and returns it to the pool
+func (l *Logger) write(info *Info) {
+	l.worker.Log(info.Level, 3, info)
+	releaseInfo(info)
+}
+
+// newInfo creates the record for a message logged by the caller `pos` levels up the stack.
+// Only the program counter of the caller is captured, the function, file & line are
+// resolved when a format or sink needs them
+func (l *Logger) newInfo(ctx context.Context, lvl LogLevel, pos int, msg string) *Info {
+	info := infoPool.Get().(*Info)
+	info.Timestamp = l.clock.Now()
+	info.ID, info.UID = l.ids.next(info.Timestamp)
+	info.Module = l.Options.Module
+	info.Level = lvl
+	info.Message = msg
+	info.Duration = l.timeElapsed(l.timer)
+	info.Elapsed = l.timeElapsed(l.started)
+	info.Fields = l.fields
+
+	var pcs [1]uintptr
+	if runtime.Callers(pos-1, pcs[:]) > 0 {
+		info.pc = pcs[0]
 	}
-	l.worker.Log(info.Level, pos, info)
+	if l.worker.config().goroutine {
+		info.Goroutine = goroutineID()
+	}
+	l.correlate(ctx, info)
+	return info
+}
+
+// correlate sets the trace & span ids of info from ctx, or the context bound to the logger
+func (l *Logger) correlate(ctx context.Context, info *Info) {
+	if ctx == nil {
+		ctx = l.ctx
+	}
+	if ctx == nil {
+		return
+	}
+	extract := l.Options.TraceExtractor
+	if extract == nil {
+		extract = SpanFromContext
+	}
+	info.TraceID, info.SpanID, _ = extract(ctx)
+}
+
+func (l *Logger) traceInternal(ctx context.Context, pos int, a ...interface{}) {
+	if !l.worker.enabled(TraceLevel, 0, l.Options.Module) {
+		return
+	}
+	a, l = l.withArgFields(a)
+	l.write(l.newInfo(ctx, TraceLevel, pos+1, sprint(a)))
+}
+
+// WithContext returns a logger sharing this logger's output whose records carry the
+// trace & span ids found in ctx
+func (l *Logger) WithContext(ctx context.Context) *Logger {
+	c := *l
+	c.ctx = ctx
+	return &c
+}
+
+// SetTraceExtractor sets the function used to find trace & span ids in a context
+func (l *Logger) SetTraceExtractor(extract TraceExtractor) {
+	l.Options.TraceExtractor = extract
 }
 
 // SetModuleName sets the name of the module being logged
@@ -207,9 +315,10 @@ func (l *Logger) SetModuleName(name string) {
 	l.Options.Module = name
 }
 
-// SetFormat ...
-func (l *Logger) SetFormat(format string) {
-	l.worker.SetFormat(format)
+// SetFormat sets the format of log messages, see the README for the placeholders. An error
+// is returned, and the current format kept, if the format contains an invalid placeholder
+func (l *Logger) SetFormat(format string) error {
+	return l.worker.SetFormat(format)
 }
 
 // SetLogLevel ...
@@ -217,6 +326,18 @@ func (l *Logger) SetLogLevel(level LogLevel) {
 	l.worker.SetLogLevel(level)
 }
 
+// SetLevelOverrides sets per module & per file levels from a spec like
+// "billing=debug,http=warn,*_cache.go=error", keeping the current ones on error
+func (l *Logger) SetLevelOverrides(spec string) error {
+	overrides, err := ParseLevelOverrides(spec)
+	if err != nil {
+		return err
+	}
+	l.Options.Levels = overrides
+	l.worker.SetLevelOverrides(overrides)
+	return nil
+}
+
 // SetFunction sets the function name of the logger
 func (l *Logger) SetFunction(name string) {
 	l.worker.SetFunction(name)
@@ -228,17 +349,14 @@ func (l *Logger) SetEnvironment(env Environment) {
 	l.worker.SetEnvironment(env)
 }
 
-// SetEnvironmentFromString is used to manually set the log environment to either development, testing or production
+// SetEnvironmentFromString sets the environment of the profile with the given name or alias
+// ("dev", "qa", "production", "staging"...), unknown names select production
 func (l *Logger) SetEnvironmentFromString(env string) {
-	env = strings.ToLower(env)
-	switch env {
-	case "dev":
-		l.SetEnvironment(EnvDevelopment)
-	case "qa":
-		l.SetEnvironment(EnvQuality)
-	default:
-		l.SetEnvironment(EnvProduction)
+	e, ok := profiles.lookup(env)
+	if !ok {
+		e = EnvProduction
 	}
+	l.SetEnvironment(e)
 }
 
 // SetOutput is used to manually set the output to send log data
@@ -250,7 +368,26 @@ func (l *Logger) SetOutput(out io.Writer) {
 // SetColor is used to manually set the color mode
 func (l *Logger) SetColor(c ColorMode) {
 	l.Options.UseColor = c
-	l.worker.color = c
+	l.worker.SetColor(c)
+}
+
+// SetTheme colors each element of records with the theme (see ThemeDark, ThemeLight &
+// ThemeHighContrast), nil colors whole lines with the color of their level
+func (l *Logger) SetTheme(theme *Theme) error {
+	if err := l.worker.SetTheme(theme); err != nil {
+		return err
+	}
+	l.Options.Theme = theme
+	return nil
+}
+
+// SetThemeFromString sets the theme registered under name ("dark", "light", "high-contrast"...)
+func (l *Logger) SetThemeFromString(name string) error {
+	theme, ok := LookupTheme(name)
+	if !ok {
+		return fmt.Errorf("golog: unknown theme %q", name)
+	}
+	return l.SetTheme(theme)
 }
 
 // UseJSONForProduction forces using JSON instead of log for production
@@ -258,6 +395,18 @@ func (l *Logger) UseJSONForProduction() {
 	l.worker.UseJSONForProduction()
 }
 
+// UsePrettyForDevelopment forces using the pretty console encoder for development
+func (l *Logger) UsePrettyForDevelopment() {
+	l.worker.UsePrettyForDevelopment()
+}
+
+// UsePrettyConsole writes records for humans: aligned columns, relative timestamps, the
+// source location as a clickable path and fields & multi-line messages on indented lines.
+// It applies until the format or the environment is changed, nil uses the default options
+func (l *Logger) UsePrettyConsole(opts *ConsoleOptions) {
+	l.worker.UsePrettyConsole(opts)
+}
+
 // Log The log command is the function available to user to log message,
 // lvl specifies the degree of the message the user wants to log, message
 // is the info user wants to log
@@ -265,6 +414,55 @@ func (l *Logger) Log(lvl LogLevel, a ...interface{}) {
 	l.logInternal(lvl, 4, a...)
 }
 
+// LogContext is like Log but also records the trace & span ids carried by ctx (see ContextWithSpan)
+func (l *Logger) LogContext(ctx context.Context, lvl LogLevel, a ...interface{}) {
+	l.logContext(ctx, lvl, 4, a...)
+}
+
+// LogFn logs the message returned by fn at the given level, fn is only called if the level is enabled
+func (l *Logger) LogFn(lvl LogLevel, fn func() string) {
+	l.logFnInternal(lvl, 4, fn)
+}
+
+// Output logs a message at the level & verbosity (for Debug records, see V) attributed to the
+// caller calldepth frames above the caller of Output, for adapters of other logging APIs
+func (l *Logger) Output(calldepth int, lvl LogLevel, verbosity int, msg string) {
+	if !l.worker.enabled(lvl, verbosity, l.Options.Module) {
+		return
+	}
+	info := l.newInfo(nil, lvl, calldepth+4, msg)
+	info.Verbosity = verbosity
+	l.write(info)
+}
+
+// WithModule returns a logger sharing this logger's output whose records are logged for the
+// module, e.g. a sub-module "shop/billing" of "shop". Level overrides apply to the new module
+func (l *Logger) WithModule(module string) *Logger {
+	c := *l
+	c.Options.Module = module
+	return &c
+}
+
+// Enabled reports if records of the level are written, so expensive arguments can be skipped
+func (l *Logger) Enabled(lvl LogLevel) bool {
+	return l.worker.enabled(lvl, 0, l.Options.Module)
+}
+
+// AddSink registers a sink that receives every record written by the logger
+func (l *Logger) AddSink(s Sink) {
+	l.worker.AddSink(s)
+}
+
+// RemoveSink unregisters a sink added with AddSink, without closing it
+func (l *Logger) RemoveSink(s Sink) {
+	l.worker.RemoveSink(s)
+}
+
+// Close flushes and closes all sinks of the logger that implement io.Closer
+func (l *Logger) Close() error {
+	return l.worker.Close()
+}
+
 // Trace is a basic timing function that will log InfoLevel duration of name
 func (l *Logger) Trace(name, file string, line int) {
 	l.timeReset()
@@ -273,7 +471,8 @@ func (l *Logger) Trace(name, file string, line int) {
 
 // Panic is just like func l.Fatal except that it is followed by a call to panic
 func (l *Logger) Panic(a ...interface{}) {
-	msg := fmt.Sprintf("%v", a...)
+	args, _ := splitFields(a)
+	msg := fmt.Sprintf("%v", args...)
 	l.logInternal(ErrorLevel, 4, a...)
 	if l.Options.Testing {
 		return
@@ -322,7 +521,12 @@ func (l *Logger) ErrorE(err error) {
 
 // Errorf logs a message at Error level using the same syntax and options as fmt.Printf
 func (l *Logger) Errorf(format string, a ...interface{}) {
-	l.logInternal(ErrorLevel, 4, fmt.Sprintf(format, a...))
+	l.logfInternal(ErrorLevel, 4, format, a...)
+}
+
+// ErrorFn logs the message returned by fn at Error level, fn is only called if the level is enabled
+func (l *Logger) ErrorFn(fn func() string) {
+	l.logFnInternal(ErrorLevel, 4, fn)
 }
 
 // Warning logs a message at Warning level
@@ -337,7 +541,12 @@ func (l *Logger) WarningE(err error) {
 
 // Warningf logs a message at Warning level using the same syntax and options as fmt.Printf
 func (l *Logger) Warningf(format string, a ...interface{}) {
-	l.logInternal(WarningLevel, 4, fmt.Sprintf(format, a...))
+	l.logfInternal(WarningLevel, 4, format, a...)
+}
+
+// WarningFn logs the message returned by fn at Warning level, fn is only called if the level is enabled
+func (l *Logger) WarningFn(fn func() string) {
+	l.logFnInternal(WarningLevel, 4, fn)
 }
 
 // Success logs a message at Success level
@@ -347,7 +556,12 @@ func (l *Logger) Success(a ...interface{}) {
 
 // Successf logs a message at Success level using the same syntax and options as fmt.Printf
 func (l *Logger) Successf(format string, a ...interface{}) {
-	l.logInternal(SuccessLevel, 4, fmt.Sprintf(format, a...))
+	l.logfInternal(SuccessLevel, 4, format, a...)
+}
+
+// SuccessFn logs the message returned by fn at Success level, fn is only called if the level is enabled
+func (l *Logger) SuccessFn(fn func() string) {
+	l.logFnInternal(SuccessLevel, 4, fn)
 }
 
 // Notice logs a message at Notice level
@@ -357,7 +571,12 @@ func (l *Logger) Notice(a ...interface{}) {
 
 // Noticef logs a message at Notice level using the same syntax and options as fmt.Printf
 func (l *Logger) Noticef(format string, a ...interface{}) {
-	l.logInternal(NoticeLevel, 4, fmt.Sprintf(format, a...))
+	l.logfInternal(NoticeLevel, 4, format, a...)
+}
+
+// NoticeFn logs the message returned by fn at Notice level, fn is only called if the level is enabled
+func (l *Logger) NoticeFn(fn func() string) {
+	l.logFnInternal(NoticeLevel, 4, fn)
 }
 
 // Info logs a message at Info level
@@ -367,7 +586,12 @@ func (l *Logger) Info(a ...interface{}) {
 
 // Infof logs a message at Info level using the same syntax and options as fmt.Printf
 func (l *Logger) Infof(format string, a ...interface{}) {
-	l.logInternal(InfoLevel, 4, fmt.Sprintf(format, a...))
+	l.logfInternal(InfoLevel, 4, format, a...)
+}
+
+// InfoFn logs the message returned by fn at Info level, fn is only called if the level is enabled
+func (l *Logger) InfoFn(fn func() string) {
+	l.logFnInternal(InfoLevel, 4, fn)
 }
 
 // Debug logs a message at Debug level
@@ -382,19 +606,113 @@ func (l *Logger) DebugE(err error) {
 
 // Debugf logs a message at Debug level using the same syntax and options as fmt.Printf
 func (l *Logger) Debugf(format string, a ...interface{}) {
-	l.logInternal(DebugLevel, 4, fmt.Sprintf(format, a...))
+	l.logfInternal(DebugLevel, 4, format, a...)
+}
+
+// DebugFn logs the message returned by fn at Debug level, fn is only called if the level is enabled
+func (l *Logger) DebugFn(fn func() string) {
+	l.logFnInternal(DebugLevel, 4, fn)
 }
 
 // HandlerLog Traces & logs a message at Debug level for a REST handler
 func (l *Logger) HandlerLog(w http.ResponseWriter, r *http.Request) {
 	l.timeReset()
-	defer l.traceInternal(4, fmt.Sprintf("%s %s %v", r.Method, r.RequestURI, l.timeElapsed(l.timer)))
+	defer l.traceInternal(RequestContext(r), 4, fmt.Sprintf("%s %s %v", r.Method, r.RequestURI, l.timeElapsed(l.timer)))
 }
 
 // HandlerLogf logs a message at Debug level using the same syntax and options as fmt.Printf
 func (l *Logger) HandlerLogf(w http.ResponseWriter, r *http.Request, format string, a ...interface{}) {
 	l.timeReset()
-	defer l.logInternal(DebugLevel, 4, fmt.Sprintf(format, a...))
+	defer l.logfInternal(DebugLevel, 4, format, a...)
+}
+
+// Middleware wraps next so every request is logged at Trace level with its method, route,
+// status code and duration. The span of a W3C traceparent header is added to the request
+// context, so handlers logging through WithContext(r.Context()) correlate with the caller's trace
+func (l *Logger) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r = r.WithContext(RequestContext(r))
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		start := l.clock.Now()
+		next.ServeHTTP(rec, r)
+		elapsed := l.timeElapsed(start)
+
+		if !l.worker.enabled(TraceLevel, 0, l.Options.Module) {
+			return
+		}
+		info := l.newInfo(r.Context(), TraceLevel, 3, fmt.Sprintf("%s %s %d %v", r.Method, r.RequestURI, rec.status, elapsed))
+		info.Method = r.Method
+		info.Route = r.URL.Path
+		info.StatusCode = rec.status
+		info.Duration = elapsed
+		l.write(info)
+	})
+}
+
+// Request describes a request logged with LogRequest, rendered by %{method}, %{route},
+// %{statuscode} & %{duration}
+type Request struct {
+	Method     string
+	Route      string
+	StatusCode int
+	Duration   time.Duration
+}
+
+// LogRequest logs a message about a request at the level, correlated with the span of ctx and
+// attributed to the caller calldepth frames above the caller of LogRequest, for the request
+// logging middlewares of other protocols
+func (l *Logger) LogRequest(ctx context.Context, calldepth int, lvl LogLevel, req Request, msg string) {
+	if !l.worker.enabled(lvl, 0, l.Options.Module) {
+		return
+	}
+	info := l.newInfo(ctx, lvl, calldepth+4, msg)
+	info.Method, info.Route, info.StatusCode, info.Duration = req.Method, req.Route, req.StatusCode, req.Duration
+	l.write(info)
+}
+
+// statusRecorder captures the status code written by a handler
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	wrote  bool // a response was started
+}
+
+// WriteHeader records the status code before passing it on
+func (r *statusRecorder) WriteHeader(code int) {
+	r.status, r.wrote = code, true
+	r.ResponseWriter.WriteHeader(code)
+}
+
+// Write records that a response was started before passing it on
+func (r *statusRecorder) Write(p []byte) (int, error) {
+	r.wrote = true
+	return r.ResponseWriter.Write(p)
+}
+
+// Flush sends the buffered response to the client, if the wrapped writer supports it
+func (r *statusRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		r.wrote = true
+		f.Flush()
+	}
+}
+
+// Hijack takes over the connection of the wrapped writer, like websocket handlers do
+func (r *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := r.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("golog: %T cannot be hijacked: %w", r.ResponseWriter, http.ErrNotSupported)
+	}
+	conn, rw, err := h.Hijack()
+	if err == nil {
+		r.wrote = true
+	}
+	return conn, rw, err
+}
+
+// Unwrap returns the wrapped writer, for http.ResponseController
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
 }
 
 // Print logs a message at directly with no level (RAW)
@@ -404,7 +722,7 @@ func (l *Logger) Print(a ...interface{}) {
 
 // Printf logs a message at Info level using the same syntax and options as fmt.Printf
 func (l *Logger) Printf(format string, a ...interface{}) {
-	l.logInternal(RawLevel, 4, fmt.Sprintf(format, a...))
+	l.logfInternal(RawLevel, 4, format, a...)
 }
 
 // StackAsError Prints this goroutine's execution stack as an error with an optional message at the begining
@@ -27,10 +27,12 @@ func TestAdvancedFormat(t *testing.T) {
 			"a}b %{filename} " + // brace with text that should be just text before verb
 			"%% %{file} " + // percent symbols before verb
 			"%{%{line} " + // percent symbol with brace before verb w/o space
-			"%{nonex_verb} %{lvl} " + // nonexistent verb berfore real verb
+			"%{lvl} " + // real verb
 			"%{incorr_verb %{level} " + // incorrect verb before real verb
-			"%{} [%{message}]" // empty verb before message in sq brackets
-	log.SetFormat(format)
+			"[%{message}]" // message in sq brackets
+	if err := log.SetFormat(format); err != nil {
+		t.Fatal(err)
+	}
 	log.Error("This is Error!")
 	now := time.Now()
 	want := fmt.Sprintf(
@@ -39,10 +41,10 @@ func TestAdvancedFormat(t *testing.T) {
 			"a{b pkgname "+
 			"a}b golog_test.go "+
 			"%%%% golog_test.go "+ // it's printf, escaping %, don't forget
-			"%%{34 "+
-			" ERR "+
+			"%%{36 "+
+			"ERR "+
 			"%%{incorr_verb ERROR "+
-			" [This is Error!]\n",
+			"[This is Error!]\n",
 		now.Format("Monday, 2006 Jan 01, 15:04:05"),
 	)
 
@@ -125,25 +127,101 @@ func TestParseFormat(t *testing.T) {
 	log := NewLogger(nil)
 	log.SetEnvironment(2)
 
-	msgFmt, tmeFmt := parseFormat("foobar")
+	spec, err := parseFormat("foobar")
 	want := fmt.Sprintf("%s, %s", defFmt, defTimeFmt)
-	have := fmt.Sprintf("%s, %s", msgFmt, tmeFmt)
+	have := fmt.Sprintf("%s, %s", spec.msgfmt, spec.timefmt)
 	if have != want {
 		t.Errorf("\nWant: %s\nHave: %s", want, have)
 	}
 
-	msgFmt, tmeFmt = parseFormat("{%.10s} - Foobar")
+	spec, err = parseFormat("{%.10s} - Foobar")
 	want = "{%%.10s} - Foobar, 2006-01-02 15:04:05"
-	have = fmt.Sprintf("%s, %s", msgFmt, tmeFmt)
+	have = fmt.Sprintf("%s, %s", spec.msgfmt, spec.timefmt)
 	if have != want {
 		t.Errorf("\nWant: %s\nHave: %s", want, have)
 	}
 
-	msgFmt, tmeFmt = parseFormat("%{id}, %{time}, %{module}, %{function}, %{filename}, %{file}, %{line}, %{level}, %{lvl}, %{message}")
+	spec, err = parseFormat("%{id}, %{time}, %{module}, %{function}, %{filename}, %{file}, %{line}, %{level}, %{lvl}, %{message}")
 	want = "%[1]d, %[2]s, %[3]s, %[4]s, %[5]s, %[5]s, %[6]d, %[7]s, %.3[7]s, %[8]s, 2006-01-02 15:04:05"
-	have = fmt.Sprintf("%s, %s", msgFmt, tmeFmt)
-	if have != want {
-		t.Errorf("\nWant: %s\nHave: %s", want, have)
+	have = fmt.Sprintf("%s, %s", spec.msgfmt, spec.timefmt)
+	if have != want || err != nil {
+		t.Errorf("\nWant: %s\nHave: %s (%v)", want, have, err)
+	}
+
+	spec, err = parseFormat("%{module:-16}|%{id:06}|%{lvl:-5}|%{level:.4}|%{time:15:04:05}|%{pid} 100%")
+	want = "%-16[3]s|%06[1]d|%-5.3[7]s|%.4[7]s|%[2]s|%[15]d 100%%, 15:04:05"
+	have = fmt.Sprintf("%s, %s", spec.msgfmt, spec.timefmt)
+	if have != want || err != nil {
+		t.Errorf("\nWant: %s\nHave: %s (%v)", want, have, err)
+	}
+
+	spec, err = parseFormat("%{level:upper:color} %{field:user:-8} %{module:title}")
+	want = "%[19]s %[20]s %[21]s"
+	if spec.msgfmt != want || len(spec.args) != 3 || !spec.colorArgs || err != nil {
+		t.Errorf("\nWant: %s\nHave: %s (%v)", want, spec.msgfmt, err)
+	}
+
+	for _, format := range []string{"%{nonex_verb} %{message}", "%{} %{message}", "%{module:bogus} %{message}", "%{field} %{message}"} {
+		if _, err = parseFormat(format); err == nil {
+			t.Errorf("Expected error for format %q", format)
+		}
+	}
+	_, err = parseFormat("%{message} %{bogus")
+	if want := "format offset 11: unclosed placeholder %{bogus"; err == nil || err.Error() != want {
+		t.Errorf("\nWant: %s\nHave: %v", want, err)
+	}
+}
+
+func TestFormatPlaceholders(t *testing.T) {
+	var buf bytes.Buffer
+	log := NewLogger(&Options{Module: "placeholders", Out: &buf})
+	log.SetEnvironment(EnvDevelopment)
+	log.SetColor(ClrDisabled)
+
+	if err := log.SetFormat("[%{module:-16}] [%{module:.5:upper}] [%{lvl:-5}] [%{level:title}] %{pid} %{hostname} %{message}"); err != nil {
+		t.Fatal(err)
+	}
+	log.WithField("user", "alice").Warning("formatted")
+	want := fmt.Sprintf("[placeholders    ] [PLACE] [WAR  ] [Warning] %d %s formatted\n", os.Getpid(), hostname)
+	if have := buf.String(); have != want {
+		t.Errorf("\nWant: %sHave: %s", want, have)
+	}
+	buf.Reset()
+
+	if err := log.SetFormat("%{field:user:-6}|%{field:missing}|%{message}"); err != nil {
+		t.Fatal(err)
+	}
+	log.WithFields(map[string]interface{}{"user": "bob", "n": 1}).Info("fields")
+	if have, want := buf.String(), "bob   ||fields\n"; have != want {
+		t.Errorf("\nWant: %sHave: %s", want, have)
+	}
+	buf.Reset()
+
+	if err := log.SetFormat("%{goroutine} %{elapsed} %{message}"); err != nil {
+		t.Fatal(err)
+	}
+	log.Info("runtime")
+	if have := buf.String(); strings.HasPrefix(have, "0 ") || !strings.HasSuffix(have, " runtime\n") {
+		t.Errorf("Unexpected goroutine/elapsed output: %s", have)
+	}
+	buf.Reset()
+
+	log.SetColor(ClrEnabled)
+	if err := log.SetFormat("%{level:color} %{message}  "); err != nil {
+		t.Fatal(err)
+	}
+	log.Error("colored")
+	if have, want := buf.String(), colors[ErrorLevel]+"ERROR\033[0m colored  \n"; have != want {
+		t.Errorf("\nWant: %qHave: %q", want, have)
+	}
+	buf.Reset()
+
+	if err := log.SetFormat("%{unknown} %{message}"); err == nil {
+		t.Error("Expected error for unknown placeholder")
+	}
+	log.Error("kept")
+	if have, want := buf.String(), colors[ErrorLevel]+"ERROR\033[0m kept  \n"; have != want {
+		t.Errorf("\nWant: %qHave: %q", want, have)
 	}
 }
 
@@ -358,6 +436,41 @@ func TestLogLevel(t *testing.T) {
 	}
 }
 
+func TestLazyLogging(t *testing.T) {
+	var buf bytes.Buffer
+	log := NewLogger(&Options{Module: "pkgname", Out: &buf})
+	log.SetEnvironment(EnvDevelopment)
+	log.SetColor(ClrDisabled)
+	log.SetFormat("%{lvl} %{file}#%{line} %{message}")
+	log.SetLogLevel(InfoLevel)
+
+	calls := 0
+	message := func() string {
+		calls++
+		return "lazy"
+	}
+	log.DebugFn(message)
+	log.LogFn(DebugLevel, message)
+	if calls != 0 || buf.Len() != 0 {
+		t.Errorf("Disabled level evaluated %d messages, wrote %q", calls, buf.String())
+	}
+	if log.Enabled(DebugLevel) || !log.Enabled(InfoLevel) || !log.Enabled(RawLevel) {
+		t.Errorf("Unexpected Enabled for level Info")
+	}
+
+	log.ErrorFn(message)
+	log.WarningFn(message)
+	log.SuccessFn(message)
+	log.NoticeFn(message)
+	log.InfoFn(message)
+	if calls != 5 {
+		t.Errorf("Want 5 evaluated messages Have %d", calls)
+	}
+	if !strings.HasPrefix(buf.String(), "ERR golog_test.go#") || strings.Count(buf.String(), " lazy\n") != 5 {
+		t.Errorf("Unexpected output %q", buf.String())
+	}
+}
+
 var golog *Logger
 
 func ServeHTTP(w http.ResponseWriter, r *http.Request) {
@@ -438,6 +551,7 @@ func BenchmarkLoggerLog(b *testing.B) {
 		},
 	}
 
+	b.ReportAllocs()
 	b.StartTimer()
 	for _, test := range tests {
 		for n := 0; n <= b.N; n++ {
@@ -0,0 +1,254 @@
+// Package gologgrpc provides gRPC interceptors logging calls to a golog Logger, and a
+// grpclog.LoggerV2 sending the internal logs of gRPC to a golog Logger
+package gologgrpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/AndrewDonelson/golog"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// Options customize the interceptors, nil uses the defaults
+type Options struct {
+	Levels map[codes.Code]golog.LogLevel // level of the calls of each status code, overriding DefaultLevel
+}
+
+// DefaultLevel returns the level of the calls ending with a status code: Trace for successes
+// & client mistakes, like the requests of Logger.Middleware, Warning for failures the client
+// may retry and Error for server failures
+func DefaultLevel(code codes.Code) golog.LogLevel {
+	switch code {
+	case codes.OK, codes.Canceled, codes.InvalidArgument, codes.NotFound, codes.AlreadyExists, codes.Unauthenticated:
+		return golog.TraceLevel
+	case codes.PermissionDenied, codes.ResourceExhausted, codes.FailedPrecondition, codes.Aborted, codes.OutOfRange:
+		return golog.WarningLevel
+	}
+	return golog.ErrorLevel
+}
+
+// level returns the level of the calls ending with the status code
+func (o *Options) level(code codes.Code) golog.LogLevel {
+	if o != nil {
+		if level, ok := o.Levels[code]; ok {
+			return level
+		}
+	}
+	return DefaultLevel(code)
+}
+
+// call is a finished call to log
+type call struct {
+	kind     string // unary, client_stream, server_stream or bidi_stream
+	method   string // full method, e.g. /helloworld.Greeter/SayHello
+	start    time.Time
+	err      error
+	peer     *peer.Peer
+	sent     int64 // bytes of the messages sent
+	received int64 // bytes of the messages received
+}
+
+// log writes the record of a call: the method is its %{route}, its kind its %{method} and the
+// status code its %{statuscode}. The peer, status name & payload sizes are fields
+func (o *Options) log(l *golog.Logger, ctx context.Context, c *call) {
+	code := status.Code(c.err)
+	level := o.level(code)
+	if !l.Enabled(level) {
+		return
+	}
+
+	elapsed := time.Since(c.start)
+	fields := []golog.Field{golog.String("grpc.code", code.String())}
+	if c.peer != nil && c.peer.Addr != nil {
+		fields = append(fields, golog.String("peer", c.peer.Addr.String()))
+	}
+	fields = append(fields, golog.Int64("grpc.sent_bytes", c.sent), golog.Int64("grpc.received_bytes", c.received))
+	if c.err != nil {
+		fields = append(fields, golog.Err(c.err))
+	}
+	req := golog.Request{Method: c.kind, Route: c.method, StatusCode: int(code), Duration: elapsed}
+	l.With(fields...).LogRequest(ctx, 0, level, req, fmt.Sprintf("%s %s %s %v", c.kind, c.method, code, elapsed))
+}
+
+// streamKind returns the kind of a stream
+func streamKind(clientStreams, serverStreams bool) string {
+	switch {
+	case clientStreams && serverStreams:
+		return "bidi_stream"
+	case clientStreams:
+		return "client_stream"
+	}
+	return "server_stream"
+}
+
+// size returns the bytes of a protobuf message, 0 for other messages
+func size(m interface{}) int64 {
+	if pm, ok := m.(proto.Message); ok {
+		return int64(proto.Size(pm))
+	}
+	return 0
+}
+
+// serverContext returns the context of a call, carrying the span of its traceparent metadata
+// unless the context already holds one (see golog.RequestContext)
+func serverContext(ctx context.Context) context.Context {
+	if _, _, ok := golog.SpanFromContext(ctx); ok {
+		return ctx
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(golog.TraceparentHeader); len(values) > 0 {
+			if traceID, spanID, ok := golog.ParseTraceparent(values[0]); ok {
+				return golog.ContextWithSpan(ctx, traceID, spanID)
+			}
+		}
+	}
+	return ctx
+}
+
+// UnaryServerInterceptor logs the unary calls received by a server. The context passed to
+// the handler carries the span of the traceparent metadata of the call
+func UnaryServerInterceptor(l *golog.Logger, opts *Options) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx = serverContext(ctx)
+		c := &call{kind: "unary", method: info.FullMethod, start: time.Now(), received: size(req)}
+		resp, err := handler(ctx, req)
+		c.err, c.sent = err, size(resp)
+		c.peer, _ = peer.FromContext(ctx)
+		opts.log(l, ctx, c)
+		return resp, err
+	}
+}
+
+// serverStream counts the bytes of the messages of a stream received by a server
+type serverStream struct {
+	grpc.ServerStream
+	ctx      context.Context
+	sent     atomic.Int64
+	received atomic.Int64
+}
+
+// Context returns the context of the stream, carrying the span of its traceparent metadata
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+// SendMsg sends a message, counting its bytes
+func (s *serverStream) SendMsg(m interface{}) error {
+	err := s.ServerStream.SendMsg(m)
+	if err == nil {
+		s.sent.Add(size(m))
+	}
+	return err
+}
+
+// RecvMsg receives a message, counting its bytes
+func (s *serverStream) RecvMsg(m interface{}) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err == nil {
+		s.received.Add(size(m))
+	}
+	return err
+}
+
+// StreamServerInterceptor logs the streams received by a server once they end
+func StreamServerInterceptor(l *golog.Logger, opts *Options) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		stream := &serverStream{ServerStream: ss, ctx: serverContext(ss.Context())}
+		c := &call{kind: streamKind(info.IsClientStream, info.IsServerStream), method: info.FullMethod, start: time.Now()}
+		err := handler(srv, stream)
+		c.err, c.sent, c.received = err, stream.sent.Load(), stream.received.Load()
+		c.peer, _ = peer.FromContext(stream.ctx)
+		opts.log(l, stream.ctx, c)
+		return err
+	}
+}
+
+// UnaryClientInterceptor logs the unary calls made by a client
+func UnaryClientInterceptor(l *golog.Logger, opts *Options) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, callOpts ...grpc.CallOption) error {
+		c := &call{kind: "unary", method: method, start: time.Now(), sent: size(req), peer: &peer.Peer{}}
+		err := invoker(ctx, method, req, reply, cc, append(callOpts, grpc.Peer(c.peer))...)
+		if c.err = err; err == nil {
+			c.received = size(reply)
+		}
+		opts.log(l, ctx, c)
+		return err
+	}
+}
+
+// clientStream counts the bytes of the messages of a stream made by a client, and logs it
+// when it ends
+type clientStream struct {
+	grpc.ClientStream
+	l             *golog.Logger
+	opts          *Options
+	ctx           context.Context
+	call          *call
+	serverStreams bool
+	sent          atomic.Int64
+	received      atomic.Int64
+	once          sync.Once
+}
+
+// SendMsg sends a message, counting its bytes
+func (s *clientStream) SendMsg(m interface{}) error {
+	err := s.ClientStream.SendMsg(m)
+	if err == nil {
+		s.sent.Add(size(m))
+	} else if !errors.Is(err, io.EOF) {
+		s.finish(err)
+	}
+	return err
+}
+
+// RecvMsg receives a message, counting its bytes. The stream ends with an error (io.EOF
+// once all messages were received) or with the single message of a client stream
+func (s *clientStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+	switch {
+	case err == nil:
+		s.received.Add(size(m))
+		if !s.serverStreams {
+			s.finish(nil)
+		}
+	case errors.Is(err, io.EOF):
+		s.finish(nil)
+	default:
+		s.finish(err)
+	}
+	return err
+}
+
+// finish logs the stream once
+func (s *clientStream) finish(err error) {
+	s.once.Do(func() {
+		s.call.err, s.call.sent, s.call.received = err, s.sent.Load(), s.received.Load()
+		s.opts.log(s.l, s.ctx, s.call)
+	})
+}
+
+// StreamClientInterceptor logs the streams made by a client once they end, when a receive
+// returns an error or io.EOF
+func StreamClientInterceptor(l *golog.Logger, opts *Options) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, callOpts ...grpc.CallOption) (grpc.ClientStream, error) {
+		c := &call{kind: streamKind(desc.ClientStreams, desc.ServerStreams), method: method, start: time.Now(), peer: &peer.Peer{}}
+		cs, err := streamer(ctx, desc, cc, method, append(callOpts, grpc.Peer(c.peer))...)
+		if err != nil {
+			c.err = err
+			opts.log(l, ctx, c)
+			return nil, err
+		}
+		return &clientStream{ClientStream: cs, l: l, opts: opts, ctx: ctx, call: c, serverStreams: desc.ServerStreams}, nil
+	}
+}
@@ -0,0 +1,107 @@
+package gologgrpc
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"testing"
+
+	"github.com/AndrewDonelson/golog"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func newLogger(buf *bytes.Buffer) *golog.Logger {
+	l := golog.NewLogger(&golog.Options{Module: "grpc", Out: buf, Levels: golog.LevelOverrides{}})
+	l.SetEnvironment(golog.EnvDevelopment)
+	_ = l.SetFormat("%{level} %{method} %{route} %{statuscode} %{traceid} %{fields}")
+	return l
+}
+
+// serve starts a health server logging to server, and returns a client logging to client
+func serve(t *testing.T, server, client *golog.Logger, opts *Options) (healthpb.HealthClient, func()) {
+	lis := bufconn.Listen(1 << 20)
+	s := grpc.NewServer(
+		grpc.UnaryInterceptor(UnaryServerInterceptor(server, opts)),
+		grpc.StreamInterceptor(StreamServerInterceptor(server, opts)),
+	)
+	hs := health.NewServer()
+	hs.SetServingStatus("shop", healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(s, hs)
+	go func() { _ = s.Serve(lis) }()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(UnaryClientInterceptor(client, opts)),
+		grpc.WithStreamInterceptor(StreamClientInterceptor(client, opts)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return healthpb.NewHealthClient(conn), func() {
+		_ = conn.Close()
+		s.GracefulStop()
+	}
+}
+
+func TestUnary(t *testing.T) {
+	var serverBuf, clientBuf bytes.Buffer
+	opts := &Options{Levels: map[codes.Code]golog.LogLevel{codes.NotFound: golog.WarningLevel}}
+	client, stop := serve(t, newLogger(&serverBuf), newLogger(&clientBuf), opts)
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(),
+		golog.TraceparentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	if _, err := client.Check(ctx, &healthpb.HealthCheckRequest{Service: "shop"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "cart"}); status.Code(err) != codes.NotFound {
+		t.Fatalf("Want NotFound Have: %v", err)
+	}
+	stop()
+
+	want := "TRACE unary /grpc.health.v1.Health/Check 0 4bf92f3577b34da6a3ce929d0e0e4736 grpc.code=OK peer=bufconn grpc.sent_bytes=2 grpc.received_bytes=6\n" +
+		"WARNING unary /grpc.health.v1.Health/Check 5  grpc.code=NotFound peer=bufconn grpc.sent_bytes=0 grpc.received_bytes=6 error=\"rpc error: code = NotFound desc = unknown service\"\n"
+	if have := serverBuf.String(); have != want {
+		t.Errorf("\nWant: %q\nHave: %q", want, have)
+	}
+	want = "TRACE unary /grpc.health.v1.Health/Check 0  grpc.code=OK peer=bufconn grpc.sent_bytes=6 grpc.received_bytes=2\n" +
+		"WARNING unary /grpc.health.v1.Health/Check 5  grpc.code=NotFound peer=bufconn grpc.sent_bytes=6 grpc.received_bytes=0 error=\"rpc error: code = NotFound desc = unknown service\"\n"
+	if have := clientBuf.String(); have != want {
+		t.Errorf("\nWant: %q\nHave: %q", want, have)
+	}
+}
+
+func TestStream(t *testing.T) {
+	var serverBuf, clientBuf bytes.Buffer
+	client, stop := serve(t, newLogger(&serverBuf), newLogger(&clientBuf), nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, err := client.Watch(ctx, &healthpb.HealthCheckRequest{Service: "shop"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stream.Recv(); err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+	if _, err := stream.Recv(); status.Code(err) != codes.Canceled {
+		t.Fatalf("Want Canceled Have: %v", err)
+	}
+	stop()
+
+	want := "TRACE server_stream /grpc.health.v1.Health/Watch 1  grpc.code=Canceled peer=bufconn grpc.sent_bytes=6 grpc.received_bytes=2 error=\"rpc error: code = Canceled desc = context canceled\"\n"
+	if have := clientBuf.String(); have != want {
+		t.Errorf("\nWant: %q\nHave: %q", want, have)
+	}
+	want = "TRACE server_stream /grpc.health.v1.Health/Watch 1  grpc.code=Canceled peer=bufconn grpc.sent_bytes=2 grpc.received_bytes=6 error=\"rpc error: code = Canceled desc = Stream has ended.\"\n"
+	if have := serverBuf.String(); have != want {
+		t.Errorf("\nWant: %q\nHave: %q", want, have)
+	}
+}
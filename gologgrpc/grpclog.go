@@ -0,0 +1,130 @@
+package gologgrpc
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/AndrewDonelson/golog"
+	"google.golang.org/grpc/grpclog"
+)
+
+// LoggerV2 is a grpclog.LoggerV2 writing the internal logs of gRPC to a golog Logger, install
+// it with grpclog.SetLoggerV2. Info, Warning & Error logs are written at the golog levels of
+// the same name and Fatal logs at Error level before exiting. V(n) reports if the golog
+// verbosity n is enabled
+type LoggerV2 struct {
+	l *golog.Logger
+}
+
+var (
+	_ grpclog.LoggerV2      = (*LoggerV2)(nil)
+	_ grpclog.DepthLoggerV2 = (*LoggerV2)(nil)
+)
+
+// NewLoggerV2 returns a grpclog.LoggerV2 writing to l
+func NewLoggerV2(l *golog.Logger) *LoggerV2 {
+	return &LoggerV2{l: l}
+}
+
+// output logs a message for the caller calldepth frames above the exported method calling it,
+// records are attributed to the callers of the grpclog functions
+func (g *LoggerV2) output(calldepth int, level golog.LogLevel, msg string) {
+	g.l.Output(calldepth+2, level, 0, strings.TrimSuffix(msg, "\n"))
+}
+
+// Info logs to the Info level
+func (g *LoggerV2) Info(args ...interface{}) {
+	g.output(1, golog.InfoLevel, fmt.Sprint(args...))
+}
+
+// Infoln logs to the Info level, arguments are handled like fmt.Println
+func (g *LoggerV2) Infoln(args ...interface{}) {
+	g.output(1, golog.InfoLevel, fmt.Sprintln(args...))
+}
+
+// Infof logs to the Info level, arguments are handled like fmt.Printf
+func (g *LoggerV2) Infof(format string, args ...interface{}) {
+	g.output(1, golog.InfoLevel, fmt.Sprintf(format, args...))
+}
+
+// Warning logs to the Warning level
+func (g *LoggerV2) Warning(args ...interface{}) {
+	g.output(1, golog.WarningLevel, fmt.Sprint(args...))
+}
+
+// Warningln logs to the Warning level, arguments are handled like fmt.Println
+func (g *LoggerV2) Warningln(args ...interface{}) {
+	g.output(1, golog.WarningLevel, fmt.Sprintln(args...))
+}
+
+// Warningf logs to the Warning level, arguments are handled like fmt.Printf
+func (g *LoggerV2) Warningf(format string, args ...interface{}) {
+	g.output(1, golog.WarningLevel, fmt.Sprintf(format, args...))
+}
+
+// Error logs to the Error level
+func (g *LoggerV2) Error(args ...interface{}) {
+	g.output(1, golog.ErrorLevel, fmt.Sprint(args...))
+}
+
+// Errorln logs to the Error level, arguments are handled like fmt.Println
+func (g *LoggerV2) Errorln(args ...interface{}) {
+	g.output(1, golog.ErrorLevel, fmt.Sprintln(args...))
+}
+
+// Errorf logs to the Error level, arguments are handled like fmt.Printf
+func (g *LoggerV2) Errorf(format string, args ...interface{}) {
+	g.output(1, golog.ErrorLevel, fmt.Sprintf(format, args...))
+}
+
+// Fatal logs to the Error level and exits
+func (g *LoggerV2) Fatal(args ...interface{}) {
+	g.output(1, golog.ErrorLevel, fmt.Sprint(args...))
+	os.Exit(1)
+}
+
+// Fatalln logs to the Error level and exits, arguments are handled like fmt.Println
+func (g *LoggerV2) Fatalln(args ...interface{}) {
+	g.output(1, golog.ErrorLevel, fmt.Sprintln(args...))
+	os.Exit(1)
+}
+
+// Fatalf logs to the Error level and exits, arguments are handled like fmt.Printf
+func (g *LoggerV2) Fatalf(format string, args ...interface{}) {
+	g.output(1, golog.ErrorLevel, fmt.Sprintf(format, args...))
+	os.Exit(1)
+}
+
+// V reports if the verbosity level is enabled, 0 being the Info level
+func (g *LoggerV2) V(level int) bool {
+	if level <= 0 {
+		return g.l.Enabled(golog.InfoLevel)
+	}
+	return g.l.V(level).Enabled()
+}
+
+// InfoDepth logs to the Info level for the caller depth frames above grpclog.InfoDepth,
+// arguments are handled like fmt.Println
+func (g *LoggerV2) InfoDepth(depth int, args ...interface{}) {
+	g.output(depth+1, golog.InfoLevel, fmt.Sprintln(args...))
+}
+
+// WarningDepth logs to the Warning level for the caller depth frames above grpclog.WarningDepth,
+// arguments are handled like fmt.Println
+func (g *LoggerV2) WarningDepth(depth int, args ...interface{}) {
+	g.output(depth+1, golog.WarningLevel, fmt.Sprintln(args...))
+}
+
+// ErrorDepth logs to the Error level for the caller depth frames above grpclog.ErrorDepth,
+// arguments are handled like fmt.Println
+func (g *LoggerV2) ErrorDepth(depth int, args ...interface{}) {
+	g.output(depth+1, golog.ErrorLevel, fmt.Sprintln(args...))
+}
+
+// FatalDepth logs to the Error level for the caller depth frames above grpclog.FatalDepth and
+// exits, arguments are handled like fmt.Println
+func (g *LoggerV2) FatalDepth(depth int, args ...interface{}) {
+	g.output(depth+1, golog.ErrorLevel, fmt.Sprintln(args...))
+	os.Exit(1)
+}
@@ -0,0 +1,37 @@
+package gologgrpc
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/AndrewDonelson/golog"
+	"google.golang.org/grpc/grpclog"
+)
+
+func TestLoggerV2(t *testing.T) {
+	var buf bytes.Buffer
+	l := golog.NewLogger(&golog.Options{Module: "grpc", Out: &buf, Levels: golog.LevelOverrides{}})
+	l.SetEnvironment(golog.EnvDevelopment)
+	_ = l.SetFormat("%{level} %{file}:%{line} %{message}")
+
+	grpclog.SetLoggerV2(NewLoggerV2(l))
+	defer grpclog.SetLoggerV2(grpclog.NewLoggerV2(io.Discard, os.Stderr, os.Stderr))
+
+	grpclog.Infof("dialing %s", "bufnet")
+	grpclog.Warningln("retrying", 2)
+	grpclog.Component("transport").Error("closed")
+
+	want := "INFO grpclog_test.go:22 dialing bufnet\n" +
+		"WARNING grpclog_test.go:23 retrying 2\n" +
+		"ERROR grpclog_test.go:24 [transport] closed\n"
+	if have := buf.String(); have != want {
+		t.Errorf("\nWant: %q\nHave: %q", want, have)
+	}
+
+	g := NewLoggerV2(l)
+	if !g.V(0) || !g.V(1) || g.V(2) {
+		t.Error("Want V(0) & V(1) enabled")
+	}
+}
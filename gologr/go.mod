@@ -0,0 +1,10 @@
+module github.com/AndrewDonelson/golog/gologr
+
+go 1.21
+
+require (
+	github.com/AndrewDonelson/golog v0.0.0-00010101000000-000000000000
+	github.com/go-logr/logr v1.4.4
+)
+
+replace github.com/AndrewDonelson/golog => ../
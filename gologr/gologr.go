@@ -0,0 +1,107 @@
+// Package gologr provides a go-logr/logr LogSink writing to a golog Logger, for libraries &
+// controllers logging through logr
+package gologr
+
+import (
+	"fmt"
+
+	"github.com/AndrewDonelson/golog"
+	"github.com/go-logr/logr"
+)
+
+// New returns a logr.Logger writing to l
+func New(l *golog.Logger) logr.Logger {
+	return logr.New(NewLogSink(l))
+}
+
+// LogSink is a logr.LogSink writing to a golog Logger. V(0) records are logged at Info level
+// and V(n) records at Debug level with verbosity n (see golog.Logger.V). Errors are logged at
+// Error level whatever their V-level, key/values become fields and names are appended to the
+// module of the logger, separated by "/"
+type LogSink struct {
+	l     *golog.Logger
+	depth int // frames between the caller & the sink, set by logr
+}
+
+var (
+	_ logr.LogSink          = (*LogSink)(nil)
+	_ logr.CallDepthLogSink = (*LogSink)(nil)
+)
+
+// NewLogSink returns a sink writing to l
+func NewLogSink(l *golog.Logger) *LogSink {
+	return &LogSink{l: l}
+}
+
+// Init receives the call depth of the logr.Logger
+func (s *LogSink) Init(info logr.RuntimeInfo) {
+	s.depth = info.CallDepth
+}
+
+// Enabled reports if records of the V-level are written
+func (s *LogSink) Enabled(level int) bool {
+	if level <= 0 {
+		return s.l.Enabled(golog.InfoLevel)
+	}
+	return s.l.V(level).Enabled()
+}
+
+// Info logs a message of the V-level with the key/values
+func (s *LogSink) Info(level int, msg string, keysAndValues ...interface{}) {
+	lvl, v := golog.LogLevel(golog.InfoLevel), 0
+	if level > 0 {
+		lvl, v = golog.DebugLevel, level
+	}
+	s.with(keysAndValues).Output(s.depth+1, lvl, v, msg)
+}
+
+// Error logs a message with the text of err at Error level, like golog.Logger.ErrorE
+func (s *LogSink) Error(err error, msg string, keysAndValues ...interface{}) {
+	switch {
+	case err == nil:
+	case msg == "":
+		msg = err.Error()
+	default:
+		msg += ": " + err.Error()
+	}
+	s.with(keysAndValues).Output(s.depth+1, golog.ErrorLevel, 0, msg)
+}
+
+// WithValues returns a sink adding the key/values to every record
+func (s *LogSink) WithValues(keysAndValues ...interface{}) logr.LogSink {
+	return &LogSink{l: s.with(keysAndValues), depth: s.depth}
+}
+
+// WithName returns a sink logging for the module of the sink followed by "/" & name
+func (s *LogSink) WithName(name string) logr.LogSink {
+	return &LogSink{l: s.l.WithModule(s.l.Options.Module + "/" + name), depth: s.depth}
+}
+
+// WithCallDepth returns a sink attributing records depth frames further up the stack
+func (s *LogSink) WithCallDepth(depth int) logr.LogSink {
+	return &LogSink{l: s.l, depth: s.depth + depth}
+}
+
+// with returns the logger with the key/values as fields. Non string keys are formatted, a
+// missing value is "(MISSING)" and values implementing logr.Marshaler are marshaled
+func (s *LogSink) with(keysAndValues []interface{}) *golog.Logger {
+	if len(keysAndValues) == 0 {
+		return s.l
+	}
+	fields := make([]golog.Field, 0, (len(keysAndValues)+1)/2)
+	for i := 0; i < len(keysAndValues); i += 2 {
+		key, ok := keysAndValues[i].(string)
+		if !ok {
+			key = fmt.Sprint(keysAndValues[i])
+		}
+		var value interface{} = "(MISSING)"
+		if i+1 < len(keysAndValues) {
+			value = keysAndValues[i+1]
+		}
+		if m, ok := value.(logr.Marshaler); ok {
+			value = m.MarshalLog()
+		}
+		fields = append(fields, golog.Any(key, value))
+	}
+	return s.l.With(fields...)
+}
@@ -0,0 +1,68 @@
+package gologr
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/AndrewDonelson/golog"
+)
+
+type secret string
+
+func (s secret) MarshalLog() interface{} {
+	return "***"
+}
+
+func newLogger(buf *bytes.Buffer) *golog.Logger {
+	l := golog.NewLogger(&golog.Options{Module: "controller", Out: buf, Levels: golog.LevelOverrides{}})
+	l.SetEnvironment(golog.EnvDevelopment)
+	_ = l.SetFormat("%{level} [%{module}] %{file}:%{line} %{message} %{fields}")
+	return l
+}
+
+func TestLogSink(t *testing.T) {
+	var buf bytes.Buffer
+	log := New(newLogger(&buf)).WithName("pods").WithValues("namespace", "default")
+
+	log.Info("reconciled", "pod", "web-1", "token", secret("t0k3n"))
+	log.V(1).Info("cache hit")
+	log.V(2).Info("filtered, the development environment writes V(1)")
+	log.Error(errors.New("conflict"), "update failed", "retry", true)
+	log.V(3).Error(nil, "errors ignore V-levels", "odd")
+
+	want := "INFO [controller/pods] gologr_test.go:28 reconciled namespace=default pod=web-1 token=***\n" +
+		"DEBUG [controller/pods] gologr_test.go:29 cache hit namespace=default\n" +
+		"ERROR [controller/pods] gologr_test.go:31 update failed: conflict namespace=default retry=true\n" +
+		"ERROR [controller/pods] gologr_test.go:32 errors ignore V-levels namespace=default odd=(MISSING)\n"
+	if have := buf.String(); have != want {
+		t.Errorf("\nWant: %q\nHave: %q", want, have)
+	}
+}
+
+func TestEnabled(t *testing.T) {
+	var buf bytes.Buffer
+	l := newLogger(&buf)
+	if err := l.SetLevelOverrides("controller/noisy=warn"); err != nil {
+		t.Fatal(err)
+	}
+	log := New(l)
+	if !log.Enabled() || !log.V(1).Enabled() || log.V(2).Enabled() {
+		t.Error("Want V(0) & V(1) enabled")
+	}
+	if noisy := log.WithName("noisy"); noisy.Enabled() {
+		t.Error("Want Info disabled by the level override of the module")
+	}
+}
+
+func TestCallDepth(t *testing.T) {
+	var buf bytes.Buffer
+	log := New(newLogger(&buf))
+	helper := func(msg string) {
+		log.WithCallDepth(1).Info(msg)
+	}
+	helper("from helper")
+	if want := "INFO [controller] gologr_test.go:64 from helper \n"; buf.String() != want {
+		t.Errorf("\nWant: %q\nHave: %q", want, buf.String())
+	}
+}
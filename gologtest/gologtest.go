@@ -0,0 +1,300 @@
+// Package gologtest provides helpers to test code logging with golog: an in-memory recording
+// sink, loggers writing to testing.T and assertions on the recorded records
+package gologtest
+
+import (
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/AndrewDonelson/golog"
+)
+
+// Entry is a copy of a record received by a Recorder
+type Entry struct {
+	ID        uint64
+	UID       string
+	Timestamp time.Time
+	Level     golog.LogLevel
+	Module    string
+	Function  string
+	Filename  string
+	Line      int
+	Message   string
+	TraceID   string
+	SpanID    string
+	Fields    []golog.Field
+}
+
+// Field returns the value of the field with the given key
+func (e Entry) Field(key string) (interface{}, bool) {
+	for i := len(e.Fields) - 1; i >= 0; i-- {
+		if e.Fields[i].Key == key {
+			return e.Fields[i].Interface(), true
+		}
+	}
+	return nil, false
+}
+
+// Recorder is a golog.Sink keeping the records it receives in memory
+type Recorder struct {
+	mu          sync.Mutex
+	entries     []Entry
+	t           testing.TB // failed on unexpected errors, nil if errors are allowed
+	allowErrors []string
+	done        bool // the test is over, records are ignored
+}
+
+// NewRecorder returns an empty recorder, register it with Logger.AddSink
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+// Write records a copy of the record
+func (r *Recorder) Write(info *golog.Info) error {
+	e := Entry{
+		ID:        info.ID,
+		UID:       info.UID,
+		Timestamp: info.Timestamp,
+		Level:     info.Level,
+		Module:    info.Module,
+		Function:  info.Function,
+		Filename:  info.Filename,
+		Line:      info.Line,
+		Message:   info.Message,
+		TraceID:   info.TraceID,
+		SpanID:    info.SpanID,
+		Fields:    append([]golog.Field(nil), info.Fields...),
+	}
+
+	r.mu.Lock()
+	if r.done {
+		r.mu.Unlock()
+		return nil
+	}
+	r.entries = append(r.entries, e)
+	fail := r.t != nil && e.Level == golog.ErrorLevel && !r.allowed(e.Message)
+	r.mu.Unlock()
+
+	if fail {
+		r.t.Errorf("unexpected error logged at %s:%d: %s", e.Filename, e.Line, e.Message)
+	}
+	return nil
+}
+
+// allowed reports if an error message contains one of the allowed substrings
+func (r *Recorder) allowed(message string) bool {
+	for _, substr := range r.allowErrors {
+		if strings.Contains(message, substr) {
+			return true
+		}
+	}
+	return false
+}
+
+// Entries returns the recorded entries
+func (r *Recorder) Entries() []Entry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Entry(nil), r.entries...)
+}
+
+// Reset discards the recorded entries
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	r.entries = nil
+	r.mu.Unlock()
+}
+
+// Filter returns the entries of the level whose message contains substr
+func (r *Recorder) Filter(level golog.LogLevel, substr string) []Entry {
+	var found []Entry
+	for _, e := range r.Entries() {
+		if e.Level == level && strings.Contains(e.Message, substr) {
+			found = append(found, e)
+		}
+	}
+	return found
+}
+
+// Option customizes the loggers & recorders created for a test
+type Option func(*config)
+
+type config struct {
+	module      string
+	failOnError bool
+	allowErrors []string
+}
+
+// Module sets the module name of the logger created by New
+func Module(name string) Option {
+	return func(c *config) {
+		c.module = name
+	}
+}
+
+// FailOnError fails the test when a record is logged at Error level, unless its message
+// contains a substring passed to AllowError
+func FailOnError() Option {
+	return func(c *config) {
+		c.failOnError = true
+	}
+}
+
+// AllowError lets FailOnError accept errors whose message contains substr
+func AllowError(substr string) Option {
+	return func(c *config) {
+		c.allowErrors = append(c.allowErrors, substr)
+	}
+}
+
+// recorders holds the recorders of each running test, for the Assert functions
+var recorders = struct {
+	sync.Mutex
+	byTest map[testing.TB][]*Recorder
+}{byTest: map[testing.TB][]*Recorder{}}
+
+// newRecorder returns a recorder registered with t until the test ends
+func newRecorder(t testing.TB, c *config) *Recorder {
+	r := NewRecorder()
+	if c.failOnError {
+		r.t = t
+		r.allowErrors = c.allowErrors
+	}
+
+	recorders.Lock()
+	recorders.byTest[t] = append(recorders.byTest[t], r)
+	recorders.Unlock()
+	t.Cleanup(func() {
+		r.mu.Lock()
+		r.done = true
+		r.mu.Unlock()
+		recorders.Lock()
+		delete(recorders.byTest, t)
+		recorders.Unlock()
+	})
+	return r
+}
+
+// testWriter writes the output of a logger to a test. The lines start with the location of
+// the logging call, so they are written with TB.Output (Go 1.25) which adds no location of its
+// own. With older versions t.Log also prefixes the lines with the location of this writer
+type testWriter struct {
+	t    testing.TB
+	out  io.Writer // TB.Output, nil if t.Log is used
+	mu   sync.Mutex
+	done bool
+}
+
+// newTestWriter returns a writer to the output of t until the test ends
+func newTestWriter(t testing.TB) *testWriter {
+	w := &testWriter{t: t}
+	if o, ok := t.(interface{ Output() io.Writer }); ok {
+		w.out = o.Output()
+	}
+	t.Cleanup(func() {
+		w.mu.Lock()
+		w.done = true
+		w.mu.Unlock()
+	})
+	return w
+}
+
+func (w *testWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.done {
+		return len(p), nil
+	}
+	line := strings.TrimRight(string(p), " \n")
+	if w.out != nil {
+		_, _ = io.WriteString(w.out, line+"\n")
+	} else {
+		w.t.Log(line)
+	}
+	return len(p), nil
+}
+
+// New returns a development logger writing to the output of t and recording its records for
+// the Assert functions. The lines start with the file & line of the logging call
+func New(t testing.TB, opts ...Option) *golog.Logger {
+	t.Helper()
+	c := &config{module: t.Name()}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	log := golog.NewLogger(&golog.Options{Module: c.module, Out: newTestWriter(t), Levels: golog.LevelOverrides{}})
+	log.SetEnvironment(golog.EnvDevelopment)
+	log.SetColor(golog.ClrDisabled)
+	if err := log.SetFormat("%{file}:%{line}: %{level:-7} [%{module}] %{message} %{fields}"); err != nil {
+		t.Fatal(err)
+	}
+	log.AddSink(newRecorder(t, c))
+	return log
+}
+
+// Attach records the records of an existing logger (such as golog.Log) until the test ends
+func Attach(t testing.TB, log *golog.Logger, opts ...Option) *Recorder {
+	t.Helper()
+	c := &config{}
+	for _, opt := range opts {
+		opt(c)
+	}
+	r := newRecorder(t, c)
+	log.AddSink(r)
+	t.Cleanup(func() {
+		log.RemoveSink(r)
+	})
+	return r
+}
+
+// entries returns the entries recorded for t by the loggers of New & Attach
+func entries(t testing.TB) []Entry {
+	t.Helper()
+	recorders.Lock()
+	list := recorders.byTest[t]
+	recorders.Unlock()
+	if len(list) == 0 {
+		t.Fatal("gologtest: no logger created with New or Attach for this test")
+	}
+
+	var all []Entry
+	for _, r := range list {
+		all = append(all, r.Entries()...)
+	}
+	return all
+}
+
+// AssertLogged fails the test if no record of the level containing substr was logged
+func AssertLogged(t testing.TB, level golog.LogLevel, substr string) {
+	t.Helper()
+	for _, e := range entries(t) {
+		if e.Level == level && strings.Contains(e.Message, substr) {
+			return
+		}
+	}
+	t.Errorf("no %s record containing %q was logged", level, substr)
+}
+
+// AssertNotLogged fails the test if a record of the level containing substr was logged
+func AssertNotLogged(t testing.TB, level golog.LogLevel, substr string) {
+	t.Helper()
+	for _, e := range entries(t) {
+		if e.Level == level && strings.Contains(e.Message, substr) {
+			t.Errorf("unexpected %s record logged at %s:%d: %s", level, e.Filename, e.Line, e.Message)
+		}
+	}
+}
+
+// AssertNoErrors fails the test if a record was logged at Error level
+func AssertNoErrors(t testing.TB) {
+	t.Helper()
+	for _, e := range entries(t) {
+		if e.Level == golog.ErrorLevel {
+			t.Errorf("unexpected error logged at %s:%d: %s", e.Filename, e.Line, e.Message)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package gologtest
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/AndrewDonelson/golog"
+)
+
+// fakeT records the failures of the assertions under test
+type fakeT struct {
+	testing.TB
+	errors []string
+	logs   []string
+}
+
+func (f *fakeT) Helper() {}
+
+func (f *fakeT) Errorf(format string, args ...interface{}) {
+	f.errors = append(f.errors, format)
+}
+
+func (f *fakeT) Output() io.Writer {
+	return writerFunc(func(p []byte) (int, error) {
+		f.logs = append(f.logs, string(p))
+		return len(p), nil
+	})
+}
+
+type writerFunc func(p []byte) (int, error)
+
+func (f writerFunc) Write(p []byte) (int, error) {
+	return f(p)
+}
+
+func TestRecorder(t *testing.T) {
+	log := New(t)
+	log.WithField("user", "ann").Info("user logged in")
+	log.Warning("disk almost full")
+
+	AssertLogged(t, golog.InfoLevel, "logged in")
+	AssertLogged(t, golog.WarningLevel, "disk")
+	AssertNotLogged(t, golog.ErrorLevel, "disk")
+	AssertNoErrors(t)
+
+	r := Attach(t, log)
+	log.Notice("attached")
+	entries := r.Entries()
+	if len(entries) != 1 || entries[0].Message != "attached" || entries[0].Module != t.Name() {
+		t.Fatalf("unexpected entries %+v", entries)
+	}
+	if entries[0].Filename != "gologtest_test.go" || entries[0].Line == 0 {
+		t.Errorf("unexpected location %s:%d", entries[0].Filename, entries[0].Line)
+	}
+
+	log.WithField("user", "bob").Info("again")
+	if got := r.Filter(golog.InfoLevel, "again"); len(got) != 1 {
+		t.Fatalf("expected 1 entry, got %d", len(got))
+	} else if user, ok := got[0].Field("user"); !ok || user != "bob" {
+		t.Errorf("expected user bob, got %v", user)
+	}
+
+	r.Reset()
+	if len(r.Entries()) != 0 {
+		t.Error("expected no entries after Reset")
+	}
+}
+
+func TestAssertions(t *testing.T) {
+	ft := &fakeT{TB: t}
+	log := New(ft)
+	_, file, line, _ := runtime.Caller(0)
+	log.Error("connection refused")
+
+	AssertLogged(ft, golog.ErrorLevel, "refused")
+	AssertLogged(ft, golog.InfoLevel, "refused")
+	AssertNotLogged(ft, golog.ErrorLevel, "connection")
+	AssertNoErrors(ft)
+	if len(ft.errors) != 3 {
+		t.Fatalf("expected 3 failures, got %q", ft.errors)
+	}
+
+	want := fmt.Sprintf("%s:%d: ERROR   [%s] connection refused\n", filepath.Base(file), line+1, t.Name())
+	if len(ft.logs) != 1 || ft.logs[0] != want {
+		t.Errorf("\nWant: %q\nHave: %q", want, ft.logs)
+	}
+}
+
+func TestFailOnError(t *testing.T) {
+	ft := &fakeT{TB: t}
+	log := New(ft, FailOnError(), AllowError("expected"), Module("payments"))
+	log.Error("expected timeout")
+	log.Warning("slow")
+	if len(ft.errors) != 0 {
+		t.Fatalf("unexpected failures %q", ft.errors)
+	}
+
+	log.ErrorE(errors.New("boom"))
+	if len(ft.errors) != 1 {
+		t.Fatalf("expected 1 failure, got %q", ft.errors)
+	}
+	if !strings.Contains(ft.logs[0], "[payments]") {
+		t.Errorf("expected module in %q", ft.logs[0])
+	}
+}
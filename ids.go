@@ -0,0 +1,157 @@
+// Package golog Simple flexible go logging
+// This file contains the generation of record ids
+package golog
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// IDStrategy selects how the ids of records (%{id}) are generated
+type IDStrategy int
+
+const (
+	// IDGlobal - One sequence shared by all loggers, wrapping after MaxLogID
+	IDGlobal IDStrategy = iota
+	// IDPerLogger - A sequence per logger (shared with the loggers derived from it), reset by Logger.ResetIDs
+	IDPerLogger
+	// IDMonotonic - 64-bit ids from the nanoseconds of the record time, increasing across loggers & restarts
+	IDMonotonic
+	// IDULID - 26 character ULIDs, unique across hosts and sorted by time (Info.UID)
+	IDULID
+	// IDUUIDv7 - RFC 9562 version 7 UUIDs, unique across hosts and sorted by time (Info.UID)
+	IDUUIDv7
+)
+
+// idSequence generates the ids of the records of a logger
+type idSequence struct {
+	strategy IDStrategy
+	n        uint64 // last id of IDPerLogger
+}
+
+// newIDSequence returns the id sequence of a strategy
+func newIDSequence(strategy IDStrategy) *idSequence {
+	return &idSequence{strategy: strategy}
+}
+
+// next returns the id of a record created at now, numeric or textual depending on the strategy
+func (s *idSequence) next(now time.Time) (uint64, string) {
+	switch s.strategy {
+	case IDPerLogger:
+		return atomic.AddUint64(&s.n, 1), ""
+	case IDMonotonic:
+		return nextMonotonic(now), ""
+	case IDULID:
+		var b [26]byte
+		ms, hi, lo := ulids.next(now)
+		return 0, string(appendULID(b[:0], ms, hi, lo))
+	case IDUUIDv7:
+		var b [36]byte
+		ms, hi, lo := uuids.next(now)
+		return 0, string(appendUUID(b[:0], ms, hi, lo))
+	}
+	return atomic.AddUint64(&logNo, 1), ""
+}
+
+// reset restarts the sequence, so the next id is 1. Only sequences can be reset
+func (s *idSequence) reset() {
+	switch s.strategy {
+	case IDPerLogger:
+		atomic.StoreUint64(&s.n, 0)
+	case IDGlobal:
+		atomic.StoreUint64(&logNo, 0)
+	}
+}
+
+// lastMonotonic is the last id of IDMonotonic
+var lastMonotonic uint64
+
+// nextMonotonic returns the nanoseconds since the epoch of now, or the last id + 1 if that is
+// not larger than the last id
+func nextMonotonic(now time.Time) uint64 {
+	t := uint64(now.UnixNano())
+	for {
+		last := atomic.LoadUint64(&lastMonotonic)
+		id := t
+		if id <= last {
+			id = last + 1
+		}
+		if atomic.CompareAndSwapUint64(&lastMonotonic, last, id) {
+			return id
+		}
+	}
+}
+
+// timeOrdered generates 128-bit ids made of a 48-bit millisecond timestamp followed by random
+// bits. Ids created in the same millisecond increment the random bits of the previous one,
+// so ids are strictly increasing
+type timeOrdered struct {
+	mu     sync.Mutex
+	ms     uint64
+	hi, lo uint64 // random bits, hiBits in hi followed by 64 in lo
+	hiBits uint
+}
+
+// ulids & uuids generate the ids of IDULID (80 random bits) & IDUUIDv7 (74 random bits)
+var (
+	ulids = &timeOrdered{hiBits: 16}
+	uuids = &timeOrdered{hiBits: 10}
+)
+
+// next returns the timestamp & random bits of a new id
+func (g *timeOrdered) next(now time.Time) (ms, hi, lo uint64) {
+	ms = uint64(now.UnixMilli()) & (1<<48 - 1)
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if ms <= g.ms {
+		ms = g.ms
+		if g.lo++; g.lo == 0 {
+			g.hi++
+		}
+		if g.hi < 1<<g.hiBits {
+			return ms, g.hi, g.lo
+		}
+		ms++ // random bits overflowed, move to the next millisecond
+	}
+
+	var b [10]byte
+	_, _ = rand.Read(b[:])
+	g.ms = ms
+	g.hi = uint64(binary.BigEndian.Uint16(b[:2])) & (1<<g.hiBits - 1)
+	g.lo = binary.BigEndian.Uint64(b[2:])
+	return ms, g.hi, g.lo
+}
+
+// crockford is the base32 alphabet of ULIDs
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// appendULID appends the 26 characters of a ULID
+func appendULID(buf []byte, ms, hi, lo uint64) []byte {
+	var text [26]byte
+	h, l := ms<<16|hi, lo
+	for i := len(text) - 1; i >= 0; i-- {
+		text[i] = crockford[l&31]
+		l = l>>5 | h<<59
+		h >>= 5
+	}
+	return append(buf, text[:]...)
+}
+
+// appendUUID appends a version 7 UUID in its 8-4-4-4-12 hex form
+func appendUUID(buf []byte, ms, hi, lo uint64) []byte {
+	const hex = "0123456789abcdef"
+	randA := hi<<2 | lo>>62 // 12 bits
+	var b [16]byte
+	binary.BigEndian.PutUint64(b[:8], ms<<16|0x7000|randA)
+	binary.BigEndian.PutUint64(b[8:], lo&(1<<62-1)|1<<63)
+	for i, c := range b {
+		if i == 4 || i == 6 || i == 8 || i == 10 {
+			buf = append(buf, '-')
+		}
+		buf = append(buf, hex[c>>4], hex[c&15])
+	}
+	return buf
+}
@@ -0,0 +1,89 @@
+package golog
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestULID(t *testing.T) {
+	// timestamp of the ULID spec example
+	if have := string(appendULID(nil, 1469922850259, 0, 0)); have != "01ARZ3NDEK0000000000000000" {
+		t.Errorf("Want: %q Have: %q", "01ARZ3NDEK0000000000000000", have)
+	}
+	if have := string(appendULID(nil, 1<<48-1, 1<<16-1, 1<<64-1)); have != "7ZZZZZZZZZZZZZZZZZZZZZZZZZ" {
+		t.Errorf("Want max ULID Have: %q", have)
+	}
+}
+
+func TestUUIDv7(t *testing.T) {
+	// example of RFC 9562
+	have := string(appendUUID(nil, 0x017F22E279B0, 0xCC3>>2, 0xCC3&3<<62|0x18C4DC0C0C07398F))
+	if want := "017f22e2-79b0-7cc3-98c4-dc0c0c07398f"; have != want {
+		t.Errorf("Want: %q Have: %q", want, have)
+	}
+}
+
+func TestIDStrategies(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	for _, test := range []struct {
+		strategy IDStrategy
+		pattern  string
+	}{
+		{IDMonotonic, `^\d{19}$`},
+		{IDULID, `^[0-9A-HJKMNP-TV-Z]{26}$`},
+		{IDUUIDv7, `^[0-9a-f]{8}-[0-9a-f]{4}-7[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`},
+	} {
+		var buf bytes.Buffer
+		log := NewLogger(&Options{Module: "ids", Out: &buf, IDs: test.strategy, Clock: NewManualClock(now), Levels: LevelOverrides{}})
+		log.SetEnvironment(EnvDevelopment)
+		_ = log.SetFormat("%{id:.6} %{message}")
+		for i := 0; i < 1000; i++ {
+			log.Info("x")
+		}
+
+		// ids created in the same instant are unique and sorted
+		lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+		re := regexp.MustCompile(test.pattern)
+		prev := ""
+		for _, line := range lines {
+			id := strings.TrimSuffix(line, " x")
+			if !re.MatchString(id) {
+				t.Fatalf("Strategy %d: invalid id %q", test.strategy, id)
+			}
+			if prev != "" && (len(id) != len(prev) || id <= prev) {
+				t.Fatalf("Strategy %d: id %q is not after %q", test.strategy, id, prev)
+			}
+			prev = id
+		}
+		if test.strategy == IDULID && !strings.HasPrefix(lines[0], string(appendULID(nil, uint64(now.UnixMilli()), 0, 0)[:10])) {
+			t.Errorf("ULID %q does not start with the time of the record", lines[0])
+		}
+	}
+}
+
+func TestIDJSON(t *testing.T) {
+	var buf bytes.Buffer
+	log := NewLogger(&Options{Module: "ids", Out: &buf, IDs: IDUUIDv7, Levels: LevelOverrides{}})
+	log.SetEnvironment(EnvDevelopment)
+	_ = log.SetFormat(FmtJSON)
+	log.Info("json")
+
+	var record struct{ ID string }
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("Invalid JSON %q: %v", buf.String(), err)
+	}
+	if len(record.ID) != 36 {
+		t.Errorf("Want a UUID Have: %q", record.ID)
+	}
+
+	buf.Reset()
+	log.SetIDStrategy(IDPerLogger)
+	log.Info("json")
+	if !strings.Contains(buf.String(), `"id":1,`) {
+		t.Errorf("Want a numeric id Have: %q", buf.String())
+	}
+}
@@ -4,33 +4,106 @@ package golog
 
 import (
 	"fmt"
+	"os"
+	"path"
+	"runtime"
 	"strings"
+	"sync"
 	"time"
 )
 
+// outputArgs is the number of arguments Info.Output passes to printf
+const outputArgs = 18
+
+var (
+	// Process id & host name, rendered by %{pid} & %{hostname}
+	pid         = os.Getpid()
+	hostname, _ = os.Hostname()
+
+	// Records created by loggers, returned once written
+	infoPool = sync.Pool{New: func() interface{} { return new(Info) }}
+)
+
 // Info class, Contains all the info on what has to logged, time is the current time, Module is the specific module
 // For which we are logging, level is the state, importance and type of message logged,
-// Message contains the string to be logged, format is the format of string to be passed to sprintf
+// Message contains the string to be logged, format is the format of string to be passed to sprintf.
+// Records created by a Logger are pooled, sinks must not retain them after Write returns
 type Info struct {
 	ID         uint64
-	Time       string
+	UID        string // text id of records with IDULID & IDUUIDv7 ids, whose ID is 0
+	Time       string // formatted Timestamp, set lazily for records created by a Logger
 	Module     string
 	Function   string
 	Level      LogLevel
 	Line       int
 	Filename   string
+	Path       string // full path of Filename, resolved along with it
 	Message    string
 	Duration   time.Duration
 	Method     string
 	StatusCode int
 	Route      string
+	Timestamp  time.Time     // moment the record was created, Time is its formatted form
+	TraceID    string        // hex encoded trace id when logged with a span in context
+	SpanID     string        // hex encoded span id when logged with a span in context
+	Goroutine  uint64        // id of the logging goroutine, only captured when the format uses %{goroutine}
+	Elapsed    time.Duration // time since the logger was created
+	Fields     []Field       // key/values attached with Logger.WithField(s)
+	Verbosity  int           // n of records logged with Logger.V(n), 0 for others
+	pc         uintptr       // program counter of the caller
+	resolved   bool          // Function, Filename, Path & Line are set from pc
 	//format   string
 }
 
+// releaseInfo returns a record created by a Logger to the pool
+func releaseInfo(r *Info) {
+	*r = Info{}
+	infoPool.Put(r)
+}
+
+// resolveCaller sets the function, file & line from the program counter captured when the
+// record was created, once
+func (r *Info) resolveCaller() {
+	if r.resolved || r.pc == 0 {
+		return
+	}
+	if caller := runtime.FuncForPC(r.pc - 1); caller != nil {
+		file, line := caller.FileLine(r.pc - 1)
+		r.Function, r.Filename, r.Path, r.Line = caller.Name(), path.Base(file), file, line
+	}
+	r.resolved = true
+}
+
 // Output Returns a proper string to be outputted for a particular info
 func (r *Info) Output(format string) string {
+	rd, ok := renderers.Load(format)
+	if !ok {
+		rd, _ = renderers.LoadOrStore(format, compileFormat(formatSpec{msgfmt: format}))
+	}
+	if rd.(*renderer) == nil {
+		return r.sprintf(format)
+	}
+
+	bp := bufPool.Get().(*[]byte)
+	buf := rd.(*renderer).render((*bp)[:0], r, nil)
+	msg := string(buf)
+	*bp = buf
+	bufPool.Put(bp)
+	return msg
+}
+
+// sprintf renders the info with fmt.Sprintf, for printf formats the renderer can not compile
+func (r *Info) sprintf(format string) string {
+	r.resolveCaller()
+	if r.Time == "" && !r.Timestamp.IsZero() {
+		r.Time = r.Timestamp.Format(defTimeFmt)
+	}
+	var id interface{} = r.ID
+	if r.UID != "" {
+		id = r.UID
+	}
 	msg := fmt.Sprintf(format,
-		r.ID,               // %[1]   // %{id}
+		id,                 // %[1]   // %{id}
 		r.Time,             // %[2]   // %{time[:fmt]}
 		r.Module,           // %[3]   // %{module}
 		r.Function,         // %[4]   // %{function}
@@ -42,6 +115,12 @@ func (r *Info) Output(format string) string {
 		r.Method,           // "%[10] // %{method}
 		r.StatusCode,       // "%[11] // %{statuscode}
 		r.Route,            // "%[12] // %{route}
+		r.TraceID,          // "%[13] // %{traceid}
+		r.SpanID,           // "%[14] // %{spanid}
+		pid,                // "%[15] // %{pid}
+		hostname,           // "%[16] // %{hostname}
+		r.Goroutine,        // "%[17] // %{goroutine}
+		r.Elapsed,          // "%[18] // %{elapsed}
 	)
 
 	// Ignore printf errors if len(args) > len(verbs)
@@ -26,7 +26,7 @@ func TestNewInfo(t *testing.T) {
 	filename = path.Base(filename)
 	info := &Info{
 		ID:       atomic.AddUint64(&logNo, 1),
-		Time:     time.Now().Format(log.worker.timeFormat),
+		Time:     time.Now().Format(log.worker.config().timeFormat),
 		Module:   log.Options.Module,
 		Function: frame.Function,
 		Level:    InfoLevel,
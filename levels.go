@@ -0,0 +1,184 @@
+// Package golog Simple flexible go logging
+// This file contains the code for per module & per file level overrides
+package golog
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// LevelsEnvVar is the environment variable holding the level overrides of loggers created
+// without Options.Levels, e.g. GOLOG_LEVELS="billing=debug,http=warn,*_cache.go=error"
+const LevelsEnvVar = "GOLOG_LEVELS"
+
+// levelNames maps the names accepted by ParseLogLevel to levels
+var levelNames = map[string]LogLevel{
+	"raw":     RawLevel,
+	"error":   ErrorLevel,
+	"err":     ErrorLevel,
+	"fatal":   ErrorLevel,
+	"trace":   TraceLevel,
+	"warning": WarningLevel,
+	"warn":    WarningLevel,
+	"success": SuccessLevel,
+	"notice":  NoticeLevel,
+	"info":    InfoLevel,
+	"debug":   DebugLevel,
+}
+
+// levelStrings are the names of the levels, from RawLevel
+var levelStrings = [...]string{"raw", "error", "trace", "warning", "success", "notice", "info", "debug"}
+
+// String returns the lower case name of the level, as accepted by ParseLogLevel
+func (l LogLevel) String() string {
+	if l >= RawLevel && l <= DebugLevel {
+		return levelStrings[l-1]
+	}
+	return "level " + strconv.Itoa(int(l))
+}
+
+// ParseLogLevel returns the level of a case insensitive level name ("warn", "DEBUG", ...)
+func ParseLogLevel(name string) (LogLevel, error) {
+	if level, ok := levelNames[strings.ToLower(strings.TrimSpace(name))]; ok {
+		return level, nil
+	}
+	return 0, fmt.Errorf("golog: unknown log level %q", name)
+}
+
+// LevelOverride sets the level of the records logged by the modules, or from the source
+// files, matching Pattern. Patterns ending in ".go" match the file name of the caller,
+// others the module name, both with path.Match syntax
+type LevelOverride struct {
+	Pattern   string
+	Level     LogLevel
+	Verbosity int // highest V(n) written, -1 keeps the verbosity of the logger
+}
+
+// file reports if the override matches source files rather than modules
+func (o LevelOverride) file() bool {
+	return strings.HasSuffix(o.Pattern, ".go")
+}
+
+// LevelOverrides is a list of level overrides where the first matching one applies. It
+// implements flag.Value so it can be set from the command line:
+//
+//	flag.Var(&opts.Levels, "log-levels", "per module/file levels, e.g. billing=debug,*_cache.go=error")
+type LevelOverrides []LevelOverride
+
+// ParseLevelOverrides parses a comma separated list of pattern=level pairs
+// ("billing=debug,http=warn,*_cache.go=error"). A number as level is a verbosity, it sets
+// the Debug level and writes V(n) records up to that number ("billing=2")
+func ParseLevelOverrides(spec string) (LevelOverrides, error) {
+	var overrides LevelOverrides
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		eq := strings.LastIndexByte(item, '=')
+		if eq <= 0 {
+			return nil, fmt.Errorf("golog: level override %q is not pattern=level", item)
+		}
+		pattern := strings.TrimSpace(item[:eq])
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("golog: level override %q: %v", item, err)
+		}
+		override := LevelOverride{Pattern: pattern, Level: DebugLevel, Verbosity: -1}
+		value := strings.TrimSpace(item[eq+1:])
+		if v, err := strconv.Atoi(value); err == nil && v >= 0 {
+			override.Verbosity = v
+		} else if override.Level, err = ParseLogLevel(value); err != nil {
+			return nil, err
+		}
+		overrides = append(overrides, override)
+	}
+	return overrides, nil
+}
+
+// levelOverridesFromEnv returns the overrides of LevelsEnvVar, ignoring an invalid value
+func levelOverridesFromEnv() LevelOverrides {
+	overrides, _ := ParseLevelOverrides(os.Getenv(LevelsEnvVar))
+	return overrides
+}
+
+// String returns the overrides in the syntax parsed by ParseLevelOverrides
+func (o LevelOverrides) String() string {
+	items := make([]string, len(o))
+	for i, override := range o {
+		if override.Level == DebugLevel && override.Verbosity >= 0 {
+			items[i] = override.Pattern + "=" + strconv.Itoa(override.Verbosity)
+		} else {
+			items[i] = override.Pattern + "=" + override.Level.String()
+		}
+	}
+	return strings.Join(items, ",")
+}
+
+// Set replaces the overrides with the parsed spec, for flag.Value
+func (o *LevelOverrides) Set(spec string) error {
+	overrides, err := ParseLevelOverrides(spec)
+	if err != nil {
+		return err
+	}
+	*o = overrides
+	return nil
+}
+
+// SetLevelOverrides sets the level overrides of the worker
+func (w *Worker) SetLevelOverrides(overrides LevelOverrides) {
+	w.update(func(c *workerConfig) {
+		c.overrides = overrides
+	})
+}
+
+// allows reports if a level & verbosity let records of the level & verbosity v through
+func allows(level LogLevel, verbosity int, lvl LogLevel, v int) bool {
+	return level >= lvl && (v == 0 || level >= DebugLevel && verbosity >= v)
+}
+
+// verbosity returns the verbosity of the override, or the worker's one if it has none
+func (o LevelOverride) verbosity(c *workerConfig) int {
+	if o.Verbosity < 0 {
+		return c.verbosity
+	}
+	return o.Verbosity
+}
+
+// enabled reports if records of the level & verbosity logged by module may be written, or
+// kept by the flight recorder. File overrides are only known once the caller is resolved, so
+// they make it permissive
+func (w *Worker) enabled(level LogLevel, v int, module string) bool {
+	c := w.config()
+	if level == RawLevel || c.recorder.allows(v) {
+		return true
+	}
+	for _, o := range c.overrides {
+		if o.file() {
+			if allows(o.Level, o.verbosity(c), level, v) {
+				return true
+			}
+		} else if ok, _ := path.Match(o.Pattern, module); ok {
+			return allows(o.Level, o.verbosity(c), level, v)
+		}
+	}
+	return allows(c.level, c.verbosity, level, v)
+}
+
+// levelFor returns the level & verbosity of the first override matching the module or the
+// file of the record, or those of the worker
+func (c *workerConfig) levelFor(info *Info) (LogLevel, int) {
+	for _, o := range c.overrides {
+		name := info.Module
+		if o.file() {
+			info.resolveCaller()
+			name = info.Filename
+		}
+		if ok, _ := path.Match(o.Pattern, name); ok {
+			return o.Level, o.verbosity(c)
+		}
+	}
+	return c.level, c.verbosity
+}
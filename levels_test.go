@@ -0,0 +1,102 @@
+package golog
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestParseLevelOverrides(t *testing.T) {
+	overrides, err := ParseLevelOverrides(" billing=debug, http=WARN,*_cache.go=error,")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := LevelOverrides{{"billing", DebugLevel, -1}, {"http", WarningLevel, -1}, {"*_cache.go", ErrorLevel, -1}}
+	if len(overrides) != len(want) {
+		t.Fatalf("Want %v Have %v", want, overrides)
+	}
+	for i := range want {
+		if overrides[i] != want[i] {
+			t.Errorf("Want %v Have %v", want[i], overrides[i])
+		}
+	}
+	if have := overrides.String(); have != "billing=debug,http=warning,*_cache.go=error" {
+		t.Errorf("Unexpected String() %q", have)
+	}
+
+	for _, spec := range []string{"billing", "=debug", "billing=loud", "[=debug"} {
+		if _, err := ParseLevelOverrides(spec); err == nil {
+			t.Errorf("%q: expected an error", spec)
+		}
+	}
+}
+
+func TestLogLevelString(t *testing.T) {
+	for _, level := range []LogLevel{RawLevel, ErrorLevel, TraceLevel, WarningLevel, SuccessLevel, NoticeLevel, InfoLevel, DebugLevel} {
+		parsed, err := ParseLogLevel(level.String())
+		if err != nil || parsed != level {
+			t.Errorf("%d: %q parsed as %d, %v", level, level.String(), parsed, err)
+		}
+	}
+	if have := LogLevel(12).String(); have != "level 12" {
+		t.Errorf("\nWant: %q\nHave: %q", "level 12", have)
+	}
+}
+
+func TestLevelOverridesFlag(t *testing.T) {
+	opts := NewDefaultOptions()
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.Var(&opts.Levels, "log-levels", "")
+	if err := fs.Parse([]string{"-log-levels", "billing=debug"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(opts.Levels) != 1 || opts.Levels[0].Level != DebugLevel {
+		t.Errorf("Unexpected overrides %v", opts.Levels)
+	}
+	if err := fs.Parse([]string{"-log-levels", "billing=nope"}); err == nil {
+		t.Errorf("Expected an error")
+	}
+}
+
+func TestLevelOverrides(t *testing.T) {
+	t.Setenv(LevelsEnvVar, "billing=debug,http*=error")
+
+	var buf bytes.Buffer
+	newLog := func(module string) *Logger {
+		log := NewLogger(&Options{Module: module, Out: &buf})
+		log.SetEnvironment(EnvQuality)
+		log.SetColor(ClrDisabled)
+		_ = log.SetFormat("%{module} %{lvl} %{message}")
+		return log
+	}
+	billing, httpd, other := newLog("billing"), newLog("httpd"), newLog("other")
+
+	billing.Debug("shown")
+	httpd.Warning("hidden")
+	httpd.Error("shown")
+	other.Info("shown")
+	other.Debug("hidden")
+	if !billing.Enabled(DebugLevel) || httpd.Enabled(WarningLevel) || other.Enabled(DebugLevel) {
+		t.Errorf("Unexpected Enabled")
+	}
+
+	if err := other.SetLevelOverrides("levels_test.go=debug"); err != nil {
+		t.Fatal(err)
+	}
+	other.Debug("shown")
+	if err := other.SetLevelOverrides("*_test.go=error,other=debug"); err != nil {
+		t.Fatal(err)
+	}
+	other.Info("hidden")
+
+	want := "billing DEB shown\nhttpd ERR shown\nother INF shown\nother DEB shown\n"
+	if have := buf.String(); have != want {
+		t.Errorf("\nWant: %sHave: %s", want, have)
+	}
+	if strings.Contains(buf.String(), "hidden") {
+		t.Errorf("Filtered records written")
+	}
+}
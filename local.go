@@ -8,87 +8,222 @@ import (
 	"os"
 	"reflect"
 	"runtime"
+	"strconv"
 	"strings"
 )
 
+// detectEnvironment returns the profile named by the BUILD_ENV os variable, production if
+// it is not set or unknown
 func detectEnvironment() Environment {
-	be, ok := os.LookupEnv("BUILD_ENV")
-	if ok {
-		be = strings.ToLower(be)
-
-		if be == "dev" {
-			return EnvDevelopment
-		} else if be == "qa" {
-			return EnvQuality
+	if be, ok := os.LookupEnv("BUILD_ENV"); ok {
+		if env, ok := profiles.lookup(be); ok {
+			return env
 		}
 	}
 
 	return EnvProduction
 }
 
-// Analyze and represent format string as printf format string and time format
-func parseFormat(format string) (msgfmt, timefmt string) {
+// formatSpec is a parsed format: a printf format over the Info.Output arguments followed
+// by the per placeholder arguments that need more than a printf verb to be rendered
+type formatSpec struct {
+	msgfmt    string      // printf format string
+	timefmt   string      // layout of %{time}
+	args      []formatArg // arguments referenced by msgfmt after the Info.Output ones
+	goroutine bool        // format uses %{goroutine}, which is costly to capture
+	colorArgs bool        // format colors placeholders itself (%{level:color})
+}
+
+// formatArg is a placeholder rendered per record with case transforms, colors or a field
+// lookup before being handed to printf
+type formatArg struct {
+	verb  string              // printf verb applied to the value, e.g. "%-16[1]s"
+	index int                 // Info.Output argument holding the value, 0 for fields
+	field string              // name of the field for %{field:name}
+	all   bool                // all fields, for %{fields}
+	conv  func(string) string // case transform, may be nil
+	color bool                // wrap in the color of the record level
+	json  bool                // encode as a JSON value
+}
+
+// sprint formats the arguments of a log call as its message, without fmt for a single string
+func sprint(a []interface{}) string {
+	if len(a) == 1 {
+		if s, ok := a[0].(string); ok {
+			return s
+		}
+	}
+	return fmt.Sprintf("%v", a...)
+}
+
+// Analyze and represent format string as printf format string, time format and the
+// arguments of placeholders that printf can not render by itself
+func parseFormat(format string) (spec formatSpec, err error) {
 	if len(format) < 10 /* (len of "%{message} */ {
-		return defFmt, defTimeFmt
+		return formatSpec{msgfmt: defFmt, timefmt: defTimeFmt}, nil
 	}
-	timefmt = defTimeFmt
+	spec.timefmt = defTimeFmt
+	pos := 0 // offset of format in the original string, for errors
 	idx := strings.IndexRune(format, '%')
 	for idx != -1 {
-		msgfmt += format[:idx]
-		format = format[idx:]
-		if len(format) > 2 {
-			if format[1] == '{' {
-				// end of curr verb pos
-				if jdx := strings.IndexRune(format, '}'); jdx != -1 {
-					// next verb pos
-					idx = strings.Index(format[1:], "%{")
-					// incorrect verb found ("...%{wefwef ...") but after
-					// this, new verb (maybe) exists ("...%{inv %{verb}...")
-					if idx != -1 && idx < jdx {
-						msgfmt += "%%"
-						format = format[1:]
-						continue
-					}
-					// get verb and arg
-					verb, arg := ph2verb(format[:jdx+1])
-					msgfmt += verb
-					// check if verb is time
-					// here you can handle args for other verbs
-					if verb == `%[2]s` && arg != "" /* %{time} */ {
-						timefmt = arg
-					}
-					format = format[jdx+1:]
-				} else {
-					format = format[1:] // TODO: Hit with test
+		spec.msgfmt += format[:idx]
+		format, pos = format[idx:], pos+idx
+		if len(format) > 2 && format[1] == '{' {
+			// end of curr verb pos
+			if jdx := strings.IndexRune(format, '}'); jdx != -1 {
+				// next verb pos
+				idx = strings.Index(format[1:], "%{")
+				// incorrect verb found ("...%{wefwef ...") but after
+				// this, new verb (maybe) exists ("...%{inv %{verb}...")
+				if idx != -1 && idx < jdx {
+					spec.msgfmt += "%%"
+					format, pos = format[1:], pos+1
+					idx = strings.IndexRune(format, '%')
+					continue
+				}
+				if err = spec.addPlaceholder(format[:jdx+1]); err != nil {
+					return formatSpec{}, fmt.Errorf("format offset %d: %w", pos, err)
 				}
+				format, pos = format[jdx+1:], pos+jdx+1
 			} else {
-				msgfmt += "%%"
-				format = format[1:]
+				return formatSpec{}, fmt.Errorf("format offset %d: unclosed placeholder %s", pos, format)
 			}
+		} else {
+			spec.msgfmt += "%%"
+			format, pos = format[1:], pos+1
 		}
 		idx = strings.IndexRune(format, '%')
 	}
-	msgfmt += format
-	return
+	spec.msgfmt += format
+	return spec, nil
 }
 
-// translate format placeholder to printf verb and some argument of placeholder
-// (now used only as time format)
-func ph2verb(ph string) (verb string, arg string) {
-	n := len(ph)
-	if n < 4 {
-		return ``, ``
+// addPlaceholder appends the printf verb of a "%{name[:arg...]}" placeholder to the spec
+func (spec *formatSpec) addPlaceholder(ph string) error {
+	name, arg := ph2verb(ph)
+	verb, ok := phfs["%{"+name+"}"]
+	if name == "field" || name == "fields" {
+		// fields are not Info.Output arguments, the value is looked up per record
+		verb, ok = "%[1]v", true
+	}
+	if !ok {
+		return fmt.Errorf("unknown placeholder %s", ph)
+	}
+
+	switch name {
+	case "time":
+		// the whole argument is the time layout, it may contain ':'
+		if arg != "" {
+			spec.timefmt = arg
+		}
+		spec.msgfmt += verb
+		return nil
+	case "goroutine":
+		spec.goroutine = true
+	}
+
+	fa := formatArg{}
+	var args []string
+	if arg != "" {
+		args = strings.Split(arg, ":")
+	}
+	fa.all = name == "fields"
+	if name == "field" {
+		if len(args) == 0 || args[0] == "" {
+			return fmt.Errorf("placeholder %s requires a field name", ph)
+		}
+		fa.field, args = args[0], args[1:]
+	}
+
+	width := ""
+	for _, a := range args {
+		switch {
+		case a == "upper":
+			fa.conv = strings.ToUpper
+		case a == "lower":
+			fa.conv = strings.ToLower
+		case a == "title":
+			fa.conv = titleCase
+		case a == "color":
+			fa.color = true
+		case a == "json":
+			fa.json = true
+		case isWidthSpec(a) && strings.HasPrefix(a, ".") && !strings.Contains(width, "."):
+			// separate precision, e.g. %{module:-16:.16}
+			width += a
+		case isWidthSpec(a):
+			width = a
+		default:
+			return fmt.Errorf("invalid argument %q of placeholder %s", a, ph)
+		}
+	}
+	verb = withWidth(verb, width)
+
+	// plain verbs are rendered by printf directly
+	if fa.field == "" && !fa.all && fa.conv == nil && !fa.color && !fa.json {
+		spec.msgfmt += verb
+		return nil
 	}
-	if ph[0] != '%' || ph[1] != '{' || ph[n-1] != '}' {
+
+	if fa.field == "" && !fa.all {
+		fa.index, _ = strconv.Atoi(verb[strings.IndexByte(verb, '[')+1 : strings.IndexByte(verb, ']')])
+	}
+	fa.verb = verb[:strings.IndexByte(verb, '[')] + "[1]" + verb[strings.IndexByte(verb, ']')+1:]
+	spec.colorArgs = spec.colorArgs || fa.color
+	spec.args = append(spec.args, fa)
+	spec.msgfmt += fmt.Sprintf("%%[%d]s", outputArgs+len(spec.args))
+	return nil
+}
+
+// split a "%{name:arg}" placeholder into its name and argument
+func ph2verb(ph string) (name string, arg string) {
+	n := len(ph)
+	if n < 3 || ph[0] != '%' || ph[1] != '{' || ph[n-1] != '}' {
 		return ``, `` // TODO: Hit with test
 	}
 	idx := strings.IndexRune(ph, ':')
 	if idx == -1 {
-		return phfs[ph], ``
+		return ph[2 : n-1], ``
 	}
-	verb = phfs[ph[:idx]+"}"]
-	arg = ph[idx+1 : n-1]
-	return
+	return ph[2:idx], ph[idx+1 : n-1]
+}
+
+// isWidthSpec reports if s is a printf width & precision ("-16", "06", ".4", "-10.10")
+func isWidthSpec(s string) bool {
+	digits, dot := 0, false
+	for i, c := range s {
+		switch {
+		case c == '-' && i == 0:
+		case c == '.' && !dot:
+			dot, digits = true, 0
+		case c >= '0' && c <= '9':
+			digits++
+		default:
+			return false
+		}
+	}
+	return digits > 0
+}
+
+// withWidth inserts a printf width & precision into a "%[n]x" verb, replacing the
+// precision of the verb (%{lvl}) only when one is given
+func withWidth(verb, width string) string {
+	if width == "" {
+		return verb
+	}
+	idx := strings.IndexByte(verb, '[')
+	if !strings.Contains(width, ".") {
+		width += verb[1:idx]
+	}
+	return "%" + width + verb[idx:]
+}
+
+// titleCase upper cases the first letter of s and lower cases the rest
+func titleCase(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + strings.ToLower(s[1:])
 }
 
 // colorString Returns a proper string to output for colored logging
@@ -96,22 +231,18 @@ func colorString(color int) string {
 	return fmt.Sprintf("\033[%dm", int(color))
 }
 
-// initColors Initializes the map of colors
+// initColors Initializes the map of colors from the 16 colors palette
 func initColors() {
-	colors = map[LogLevel]string{
-		RawLevel:     colorString(White),
-		InfoLevel:    colorString(White),
-		ErrorLevel:   colorString(Red),
-		SuccessLevel: colorString(Green),
-		WarningLevel: colorString(Yellow),
-		NoticeLevel:  colorString(Blue),
-		DebugLevel:   colorString(Cyan),
-		TraceLevel:   colorString(Magenta),
+	colors = map[LogLevel]string{}
+	for level := LogLevel(RawLevel); level <= DebugLevel; level++ {
+		colors[level] = palettes[ColorBasic].level(level)
 	}
 }
 
 // initFormatPlaceholders Initializes the map of placeholders
-// "%{id}, %{time}, %{module}, %{function}, %{filename}, %{file}, %{line}, %{level}, %{lvl}, %{message}"
+// "%{id}, %{time}, %{module}, %{function}, %{filename}, %{file}, %{line}, %{level}, %{lvl}, %{message}",
+// "%{duration}, %{method}, %{statuscode}, %{route}, %{traceid}, %{spanid}, %{pid}, %{hostname}, %{goroutine}",
+// "%{elapsed}, %{field:name}, %{fields}"
 func initFormatPlaceholders() {
 	phfs = map[string]string{
 		"%{id}":         "%[1]d",
@@ -128,9 +259,27 @@ func initFormatPlaceholders() {
 		"%{method}":     "%[10]s",
 		"%{statuscode}": "%[11]d",
 		"%{route}":      "%[12]s",
+		"%{traceid}":    "%[13]s",
+		"%{spanid}":     "%[14]s",
+		"%{pid}":        "%[15]d",
+		"%{hostname}":   "%[16]s",
+		"%{goroutine}":  "%[17]d",
+		"%{elapsed}":    "%[18]s",
 	}
 }
 
+// goroutineID returns the id of the calling goroutine, parsed from the "goroutine N [" stack header
+func goroutineID() uint64 {
+	var buf [64]byte
+	n := runtime.Stack(buf[:], false)
+	s := strings.TrimPrefix(string(buf[:n]), "goroutine ")
+	if idx := strings.IndexByte(s, ' '); idx != -1 {
+		s = s[:idx]
+	}
+	id, _ := strconv.ParseUint(s, 10, 64)
+	return id
+}
+
 // GetCaller helper function to get the function name, filename and line number
 func GetCaller(skipLevels int) (function, file string, line int) {
 	fpcs := make([]uintptr, 1)
@@ -150,8 +299,8 @@ func GetCaller(skipLevels int) (function, file string, line int) {
 // Stack Returns a string with the execution stack for this goroutine
 func Stack() string {
 	buf := make([]byte, 1000000)
-	runtime.Stack(buf, false)
-	return string(buf)
+	n := runtime.Stack(buf, false)
+	return string(buf[:n])
 }
 
 // PrettyPrint is used to display any type nicely in the log output
@@ -166,6 +315,14 @@ func PrettyPrint(v interface{}) string {
 	return fmt.Sprintf("Dump of [%s]:\n%s\n", name, string(b))
 }
 
+// PrettyPrintFn returns a function calling PrettyPrint, for the Fn logging methods
+// (log.DebugFn(golog.PrettyPrintFn(v))) so v is only marshalled if the record is written
+func PrettyPrintFn(v interface{}) func() string {
+	return func() string {
+		return PrettyPrint(v)
+	}
+}
+
 // GetType will return the name of the provided interface using reflection
 func GetType(i interface{}) string {
 	t := reflect.TypeOf(i)
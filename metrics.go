@@ -0,0 +1,255 @@
+// Package golog Simple flexible go logging
+// This file contains the metrics on logging activity & their Prometheus exposition
+package golog
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// OutputSink is the sink label of the metrics of the formatted output of loggers
+const OutputSink = "output"
+
+// DefWriteBuckets are the upper bounds in seconds of the buckets of the write latency histograms,
+// changes apply to the histograms of the sinks written to afterwards
+var DefWriteBuckets = []float64{.00001, .000025, .00005, .0001, .00025, .0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1}
+
+// Reasons of the records counted as dropped, indexes of dropReasons
+const (
+	dropSampled      = iota // rejected by the sampling of the environment profile
+	dropQueueFull           // an OTLPExporter failed with ErrOTLPQueueFull
+	dropOutputFailed        // the output failed to write the formatted record
+)
+
+// dropReasons are the reason labels of the dropped records counter
+var dropReasons = [...]string{"sampled", "otlp_queue_full", "output_write_failed"}
+
+// metricKey identifies the records counters of a level & module
+type metricKey struct {
+	level  LogLevel
+	module string
+}
+
+// histogram counts observations in cumulative buckets
+type histogram struct {
+	bounds []float64 // upper bounds of the buckets, a copy of DefWriteBuckets
+	counts []uint64  // per bucket of bounds, then +Inf
+	sum    uint64    // float64 bits of the sum of the observations
+}
+
+// newHistogram returns an empty histogram with the buckets of DefWriteBuckets
+func newHistogram() histogram {
+	bounds := append([]float64(nil), DefWriteBuckets...)
+	sort.Float64s(bounds)
+	return histogram{bounds: bounds, counts: make([]uint64, len(bounds)+1)}
+}
+
+// observe adds a value to the histogram
+func (h *histogram) observe(v float64) {
+	i := sort.SearchFloat64s(h.bounds, v)
+	atomic.AddUint64(&h.counts[i], 1)
+	for {
+		old := atomic.LoadUint64(&h.sum)
+		if atomic.CompareAndSwapUint64(&h.sum, old, math.Float64bits(math.Float64frombits(old)+v)) {
+			return
+		}
+	}
+}
+
+// sinkMetrics are the write errors & latency of a sink
+type sinkMetrics struct {
+	errors  uint64
+	latency histogram
+}
+
+// Metrics counts the records written by loggers by level & module, the records dropped by
+// sampling, the records dropped by reason, the write errors of sinks & the latency of their writes. It is an http.Handler
+// serving them in the Prometheus text exposition format. A Metrics can be shared by loggers
+type Metrics struct {
+	mu      sync.RWMutex
+	records map[metricKey]*uint64
+	sampled map[metricKey]*uint64
+	sinks   map[string]*sinkMetrics
+	dropped [len(dropReasons)]uint64
+}
+
+// NewMetrics returns empty metrics, register them with Options.Metrics or Logger.SetMetrics
+func NewMetrics() *Metrics {
+	return &Metrics{
+		records: make(map[metricKey]*uint64),
+		sampled: make(map[metricKey]*uint64),
+		sinks:   make(map[string]*sinkMetrics),
+	}
+}
+
+// counter returns the counter of key in counters, creating it if needed
+func (m *Metrics) counter(counters map[metricKey]*uint64, key metricKey) *uint64 {
+	m.mu.RLock()
+	c, ok := counters[key]
+	m.mu.RUnlock()
+	if ok {
+		return c
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if c, ok = counters[key]; !ok {
+		c = new(uint64)
+		counters[key] = c
+	}
+	return c
+}
+
+// record counts a written record
+func (m *Metrics) record(info *Info) {
+	atomic.AddUint64(m.counter(m.records, metricKey{info.Level, info.Module}), 1)
+}
+
+// sample counts a record dropped by sampling
+func (m *Metrics) sample(info *Info) {
+	atomic.AddUint64(m.counter(m.sampled, metricKey{info.Level, info.Module}), 1)
+}
+
+// drop counts a record dropped for one of dropReasons
+func (m *Metrics) drop(reason int) {
+	atomic.AddUint64(&m.dropped[reason], 1)
+}
+
+// write records the latency & error of a write to a sink
+func (m *Metrics) write(sink string, elapsed time.Duration, err error) {
+	m.mu.RLock()
+	sm, ok := m.sinks[sink]
+	m.mu.RUnlock()
+	if !ok {
+		m.mu.Lock()
+		if sm, ok = m.sinks[sink]; !ok {
+			sm = &sinkMetrics{latency: newHistogram()}
+			m.sinks[sink] = sm
+		}
+		m.mu.Unlock()
+	}
+	if err != nil {
+		atomic.AddUint64(&sm.errors, 1)
+	}
+	sm.latency.observe(elapsed.Seconds())
+}
+
+// sinkName returns the sink label of a sink, its type
+func sinkName(s Sink) string {
+	return reflect.TypeOf(s).String()
+}
+
+// ServeHTTP writes the metrics in the Prometheus text exposition format
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_, _ = m.WriteTo(w)
+}
+
+// WriteTo writes the metrics in the Prometheus text exposition format, sorted by labels
+func (m *Metrics) WriteTo(w io.Writer) (int64, error) {
+	cw := &countingWriter{w: w}
+	bw := bufio.NewWriter(cw)
+
+	m.mu.RLock()
+	writeRecordCounters(bw, "golog_records_total", "Records written by level & module", m.records)
+	writeRecordCounters(bw, "golog_records_sampled_total", "Records dropped by sampling by level & module", m.sampled)
+	bw.WriteString("# HELP golog_records_dropped_total Records dropped by reason\n# TYPE golog_records_dropped_total counter\n")
+	for i, reason := range dropReasons {
+		bw.WriteString("golog_records_dropped_total{reason=" + quoteLabel(reason) + "} ")
+		bw.WriteString(strconv.FormatUint(atomic.LoadUint64(&m.dropped[i]), 10) + "\n")
+	}
+
+	sinks := make([]string, 0, len(m.sinks))
+	for sink := range m.sinks {
+		sinks = append(sinks, sink)
+	}
+	sort.Strings(sinks)
+	bw.WriteString("# HELP golog_sink_errors_total Failed writes by sink\n# TYPE golog_sink_errors_total counter\n")
+	for _, sink := range sinks {
+		bw.WriteString("golog_sink_errors_total{sink=" + quoteLabel(sink) + "} ")
+		bw.WriteString(strconv.FormatUint(atomic.LoadUint64(&m.sinks[sink].errors), 10) + "\n")
+	}
+	bw.WriteString("# HELP golog_write_duration_seconds Latency of the writes by sink\n# TYPE golog_write_duration_seconds histogram\n")
+	for _, sink := range sinks {
+		writeHistogram(bw, "golog_write_duration_seconds", "sink="+quoteLabel(sink), &m.sinks[sink].latency)
+	}
+	m.mu.RUnlock()
+
+	err := bw.Flush()
+	return cw.n, err
+}
+
+// writeRecordCounters writes counters labeled by level & module
+func writeRecordCounters(bw *bufio.Writer, name, help string, counters map[metricKey]*uint64) {
+	keys := make([]metricKey, 0, len(counters))
+	for key := range counters {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].module != keys[j].module {
+			return keys[i].module < keys[j].module
+		}
+		return keys[i].level < keys[j].level
+	})
+
+	bw.WriteString("# HELP " + name + " " + help + "\n# TYPE " + name + " counter\n")
+	for _, key := range keys {
+		level := "unknown"
+		if key.level >= RawLevel && key.level <= DebugLevel {
+			level = key.level.String()
+		}
+		bw.WriteString(name + "{level=\"" + level + "\",module=" + quoteLabel(key.module) + "} ")
+		bw.WriteString(strconv.FormatUint(atomic.LoadUint64(counters[key]), 10) + "\n")
+	}
+}
+
+// writeHistogram writes the cumulative buckets, sum & count of a histogram
+func writeHistogram(bw *bufio.Writer, name, labels string, h *histogram) {
+	var count uint64
+	for i, bound := range h.bounds {
+		count += atomic.LoadUint64(&h.counts[i])
+		bw.WriteString(name + "_bucket{" + labels + ",le=\"" + strconv.FormatFloat(bound, 'g', -1, 64) + "\"} ")
+		bw.WriteString(strconv.FormatUint(count, 10) + "\n")
+	}
+	count += atomic.LoadUint64(&h.counts[len(h.bounds)])
+	bw.WriteString(name + "_bucket{" + labels + ",le=\"+Inf\"} " + strconv.FormatUint(count, 10) + "\n")
+	bw.WriteString(name + "_sum{" + labels + "} ")
+	bw.WriteString(strconv.FormatFloat(math.Float64frombits(atomic.LoadUint64(&h.sum)), 'g', -1, 64) + "\n")
+	bw.WriteString(name + "_count{" + labels + "} " + strconv.FormatUint(count, 10) + "\n")
+}
+
+// labelEscaper escapes label values of the text exposition format
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// quoteLabel returns a quoted label value
+func quoteLabel(value string) string {
+	return `"` + labelEscaper.Replace(value) + `"`
+}
+
+// countingWriter counts the bytes written to w
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// SetMetrics counts the activity of the logger, and of the loggers sharing its worker, in m.
+// nil stops counting
+func (l *Logger) SetMetrics(m *Metrics) {
+	l.worker.update(func(c *workerConfig) {
+		c.metrics = m
+	})
+}
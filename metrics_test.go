@@ -0,0 +1,127 @@
+package golog
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// failingSink fails every write
+type failingSink struct{}
+
+func (failingSink) Write(info *Info) error {
+	return errors.New("unavailable")
+}
+
+func TestMetrics(t *testing.T) {
+	m := NewMetrics()
+	l := NewLogger(&Options{Module: "billing", Out: &bytes.Buffer{}, Levels: LevelOverrides{}, Metrics: m})
+	l.SetEnvironment(EnvDevelopment)
+	l.AddSink(failingSink{})
+	l.worker.update(func(c *workerConfig) { c.sampler = newSampler(&Sampling{Initial: 2}) })
+
+	for i := 0; i < 3; i++ {
+		l.Error("payment failed")
+	}
+	l.WithModule("db \"main\"").Info("connected")
+	l.V(5).Info("filtered records are not counted")
+
+	rec := httptest.NewRecorder()
+	m.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("Want the text exposition format Have: %q", ct)
+	}
+
+	want := `# HELP golog_records_total Records written by level & module
+# TYPE golog_records_total counter
+golog_records_total{level="error",module="billing"} 2
+golog_records_total{level="info",module="db \"main\""} 1
+# HELP golog_records_sampled_total Records dropped by sampling by level & module
+# TYPE golog_records_sampled_total counter
+golog_records_sampled_total{level="error",module="billing"} 1
+# HELP golog_records_dropped_total Records dropped by reason
+# TYPE golog_records_dropped_total counter
+golog_records_dropped_total{reason="sampled"} 1
+golog_records_dropped_total{reason="otlp_queue_full"} 0
+golog_records_dropped_total{reason="output_write_failed"} 0
+# HELP golog_sink_errors_total Failed writes by sink
+# TYPE golog_sink_errors_total counter
+golog_sink_errors_total{sink="golog.failingSink"} 3
+golog_sink_errors_total{sink="output"} 0
+# HELP golog_write_duration_seconds Latency of the writes by sink
+# TYPE golog_write_duration_seconds histogram
+`
+	have := rec.Body.String()
+	if !strings.HasPrefix(have, want) {
+		t.Errorf("\nWant: %q\nHave: %q", want, have)
+	}
+	for _, line := range []string{
+		`golog_write_duration_seconds_bucket{sink="output",le="+Inf"} 3` + "\n",
+		`golog_write_duration_seconds_count{sink="golog.failingSink"} 3` + "\n",
+	} {
+		if !strings.Contains(have, line) {
+			t.Errorf("Want: %q\nHave: %q", line, have)
+		}
+	}
+}
+
+func TestMetricsBucketsChanged(t *testing.T) {
+	defer func(buckets []float64) { DefWriteBuckets = buckets }(DefWriteBuckets)
+	m := NewMetrics()
+	m.write(OutputSink, time.Millisecond, nil)
+
+	DefWriteBuckets = append(DefWriteBuckets, 2, 5)
+	m.write(OutputSink, 3*time.Second, nil)
+	m.write("golog.failingSink", 3*time.Second, nil)
+
+	var buf bytes.Buffer
+	if _, err := m.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{
+		`golog_write_duration_seconds_bucket{sink="output",le="1"} 1` + "\n",
+		`golog_write_duration_seconds_bucket{sink="output",le="+Inf"} 2` + "\n",
+		`golog_write_duration_seconds_bucket{sink="golog.failingSink",le="5"} 1` + "\n",
+	} {
+		if !strings.Contains(buf.String(), line) {
+			t.Errorf("Want: %q\nHave: %q", line, buf.String())
+		}
+	}
+	if strings.Contains(buf.String(), `sink="output",le="5"`) {
+		t.Errorf("Want the buckets of the output kept Have: %q", buf.String())
+	}
+}
+
+// queueFullSink fails like an OTLPExporter whose queue is full
+type queueFullSink struct{}
+
+func (queueFullSink) Write(info *Info) error {
+	return fmt.Errorf("%w: connection refused", ErrOTLPQueueFull)
+}
+
+func TestMetricsDropped(t *testing.T) {
+	m := NewMetrics()
+	l := NewLogger(&Options{Module: "billing", Out: fullDisk{}, Levels: LevelOverrides{}, Metrics: m})
+	l.SetEnvironment(EnvDevelopment)
+	l.AddSink(queueFullSink{})
+	l.Info("dropped")
+	l.Info("dropped again")
+
+	var buf bytes.Buffer
+	if _, err := m.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{
+		`golog_records_dropped_total{reason="sampled"} 0` + "\n",
+		`golog_records_dropped_total{reason="otlp_queue_full"} 2` + "\n",
+		`golog_records_dropped_total{reason="output_write_failed"} 2` + "\n",
+	} {
+		if !strings.Contains(buf.String(), line) {
+			t.Errorf("Want: %q\nHave: %q", line, buf.String())
+		}
+	}
+}
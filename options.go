@@ -29,6 +29,8 @@ const (
 	// EnvProduction - Error level & higher, no color, minimum information
 	// Log only these levels: Warning, Error and RAW
 	EnvProduction
+
+	// Environments of profiles added with RegisterProfile follow
 )
 
 // ColorMode enumeration
@@ -41,7 +43,7 @@ const (
 	ClrDisabled
 	// ClrEnabled - Force use of color. Overrides defaults
 	ClrEnabled
-	// ClrAuto - Use color based on detected (or set) Environment
+	// ClrAuto - Use color when the output supports it (see DetectColorSupport)
 	ClrAuto
 )
 
@@ -55,6 +57,17 @@ type Options struct {
 	FmtProd     string      // for use with production environment
 	FmtDev      string      // for use with development environment
 	Testing     bool        // This is set to true if go testing is detected
+
+	TraceExtractor TraceExtractor  // Finds trace & span ids in a context, defaults to SpanFromContext
+	Levels         LevelOverrides  // Per module & per file levels, defaults to the GOLOG_LEVELS env var
+	Theme          *Theme          // Colors each element of records, nil colors whole lines
+	Clock          Clock           // Times records, defaults to the system clock
+	IDs            IDStrategy      // How record ids are generated, defaults to IDGlobal
+	Deterministic  bool            // Per logger ids, a stopped clock & no color detection for golden tests
+	Metrics        *Metrics        // Counts the records & sink writes of the logger, see NewMetrics
+	ErrorHandler   func(error)     // Called with a *WriteError when the output or a sink fails, see SetErrorHandler
+	Fallback       io.Writer       // Receives the records the output failed to write, like os.Stderr
+	FlightRecorder *FlightRecorder // Keeps the last records at every level, dumped on errors
 }
 
 // NewDefaultOptions returns a new Options object with all defaults
@@ -79,7 +92,8 @@ func NewCustomOptions(module string, env Environment, clr ColorMode, SmartError
 		o.Module = module
 	}
 
-	if env == EnvProduction || env == EnvQuality || env == EnvDevelopment {
+	// any registered environment, EnvAuto & unknown ones keep the default
+	if _, ok := profiles.profile(env); ok {
 		o.Environment = env
 	}
 
@@ -104,13 +118,15 @@ func NewCustomOptions(module string, env Environment, clr ColorMode, SmartError
 	return o
 }
 
-// EnvAsString returns the current envirnment for options as a string
+// EnvAsString returns the current environment for options as a string, "Env" followed by
+// the name of its profile (EnvDevelopment, EnvStaging...)
 func (o *Options) EnvAsString() string {
-	environments := [...]string{
-		"EvnAuto",
-		"EnvDevelopment",
-		"EnvQuality",
-		"EnvProduction",
+	if o.Environment == EnvAuto {
+		return "EnvAuto"
+	}
+	p, ok := profiles.profile(o.Environment)
+	if !ok {
+		return "EnvUnknown"
 	}
-	return environments[o.Environment]
+	return "Env" + titleCase(p.Name)
 }
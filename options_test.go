@@ -12,8 +12,8 @@ func TestEnvStrings(t *testing.T) {
 	o := Options{}
 	o.Environment = EnvAuto
 	s = o.EnvAsString()
-	if s != "EvnAuto" {
-		t.Errorf("\nWant: %sHave: %s", "EvnAuto", s)
+	if s != "EnvAuto" {
+		t.Errorf("\nWant: %sHave: %s", "EnvAuto", s)
 	}
 
 	o.Environment = EnvDevelopment
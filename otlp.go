@@ -0,0 +1,381 @@
+// Package golog Simple flexible go logging
+// This file contains the OpenTelemetry log data model & OTLP/HTTP JSON exporter
+package golog
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultOTLPEndpoint is the standard OTLP/HTTP logs endpoint of a local collector
+	DefaultOTLPEndpoint = "http://localhost:4318/v1/logs"
+
+	// DefaultOTLPBatchSize is the number of records that triggers an export
+	DefaultOTLPBatchSize = 512
+
+	// DefaultOTLPFlushInterval is the maximum time a record waits before being exported
+	DefaultOTLPFlushInterval = 5 * time.Second
+
+	// DefaultOTLPMaxPending is the number of records kept while exports fail
+	DefaultOTLPMaxPending = 8 * DefaultOTLPBatchSize
+
+	// otlpScopeName is the instrumentation scope reported with every export
+	otlpScopeName = "github.com/AndrewDonelson/golog"
+)
+
+// OTelAnyValue is an OpenTelemetry AnyValue. Strings, booleans, integers and floats map
+// to their typed OTLP value, object & array marshalers to their JSON text and anything
+// else is exported as its string representation
+type OTelAnyValue struct {
+	Value interface{}
+}
+
+// MarshalJSON encodes the value using the OTLP/JSON protobuf mapping
+func (v OTelAnyValue) MarshalJSON() ([]byte, error) {
+	switch x := v.Value.(type) {
+	case string:
+		return json.Marshal(map[string]string{"stringValue": x})
+	case bool:
+		return json.Marshal(map[string]bool{"boolValue": x})
+	case int:
+		return json.Marshal(map[string]string{"intValue": strconv.FormatInt(int64(x), 10)})
+	case int64:
+		return json.Marshal(map[string]string{"intValue": strconv.FormatInt(x, 10)})
+	case uint64:
+		return json.Marshal(map[string]string{"intValue": strconv.FormatUint(x, 10)})
+	case float64:
+		return json.Marshal(map[string]float64{"doubleValue": x})
+	case ObjectMarshaler:
+		enc := jsonEncoder{}
+		_ = enc.appendObject(x)
+		return json.Marshal(map[string]string{"stringValue": string(enc.buf)})
+	case ArrayMarshaler:
+		enc := jsonEncoder{}
+		_ = enc.appendArray(x)
+		return json.Marshal(map[string]string{"stringValue": string(enc.buf)})
+	default:
+		return json.Marshal(map[string]string{"stringValue": fmt.Sprint(x)})
+	}
+}
+
+// OTelKeyValue is an OpenTelemetry attribute
+type OTelKeyValue struct {
+	Key   string       `json:"key"`
+	Value OTelAnyValue `json:"value"`
+}
+
+// OTelLogRecord is a golog record expressed in the OpenTelemetry LogRecord data model
+type OTelLogRecord struct {
+	TimeUnixNano         uint64         `json:"timeUnixNano,string"`
+	ObservedTimeUnixNano uint64         `json:"observedTimeUnixNano,string"`
+	SeverityNumber       int            `json:"severityNumber"`
+	SeverityText         string         `json:"severityText"`
+	Body                 OTelAnyValue   `json:"body"`
+	Attributes           []OTelKeyValue `json:"attributes,omitempty"`
+	TraceID              string         `json:"traceId,omitempty"`
+	SpanID               string         `json:"spanId,omitempty"`
+}
+
+// otelSeverityNames are the short names of the ranges of 4 OpenTelemetry severity numbers
+var otelSeverityNames = [...]string{"TRACE", "DEBUG", "INFO", "WARN", "ERROR", "FATAL"}
+
+// OTelSeverity maps a golog level onto the OpenTelemetry severity number & text. The numbers
+// are the slog levels of SlogLevel plus 9, so both exports order the levels alike: Trace is
+// exported between Warn & Error
+func OTelSeverity(level LogLevel) (number int, text string) {
+	if level <= RawLevel || level > DebugLevel {
+		return 0, "RAW"
+	}
+	number = int(SlogLevel(level, 0)) + 9
+	text = otelSeverityNames[(number-1)/4]
+	if n := (number - 1) % 4; n > 0 {
+		text += strconv.Itoa(n + 1)
+	}
+	return number, text
+}
+
+// OTelRecord converts the info into an OpenTelemetry LogRecord. The module is not part
+// of the record, it is reported as the service.name of the resource by the exporter
+func (r *Info) OTelRecord() OTelLogRecord {
+	number, text := OTelSeverity(r.Level)
+	rec := OTelLogRecord{
+		ObservedTimeUnixNano: uint64(time.Now().UnixNano()),
+		SeverityNumber:       number,
+		SeverityText:         text,
+		Body:                 OTelAnyValue{r.Message},
+		TraceID:              r.TraceID,
+		SpanID:               r.SpanID,
+	}
+	if !r.Timestamp.IsZero() {
+		rec.TimeUnixNano = uint64(r.Timestamp.UnixNano())
+	}
+
+	attr := func(key string, value interface{}) {
+		rec.Attributes = append(rec.Attributes, OTelKeyValue{Key: key, Value: OTelAnyValue{value}})
+	}
+	attr("log.record.id", r.ID)
+	if r.Function != "" {
+		attr("code.function", r.Function)
+	}
+	if r.Filename != "" {
+		attr("code.filepath", r.Filename)
+		attr("code.lineno", r.Line)
+	}
+	if r.Method != "" {
+		attr("http.request.method", r.Method)
+	}
+	if r.StatusCode != 0 {
+		attr("http.response.status_code", r.StatusCode)
+	}
+	if r.Route != "" {
+		attr("http.route", r.Route)
+	}
+	for _, f := range r.Fields {
+		attr(f.Key, f.Interface())
+	}
+	return rec
+}
+
+// OTLPOptions allow customization of the OTLP exporter
+type OTLPOptions struct {
+	Endpoint      string            // Collector logs endpoint, defaults to DefaultOTLPEndpoint
+	Headers       map[string]string // Extra HTTP headers sent with every export (e.g. auth)
+	BatchSize     int               // Records that trigger an export, defaults to DefaultOTLPBatchSize
+	FlushInterval time.Duration     // Max delay before pending records are exported, defaults to DefaultOTLPFlushInterval
+	MaxPending    int               // Records kept while exports fail, defaults to DefaultOTLPMaxPending
+	Client        *http.Client      // HTTP client used for exports, defaults to a client with a 10s timeout
+}
+
+// ErrOTLPQueueFull is returned by OTLPExporter.Write when MaxPending records wait for the
+// collector, wrapping the error of the last export
+var ErrOTLPQueueFull = errors.New("golog: otlp queue full")
+
+// otlpEntry is a pending record along with the service it was logged by
+type otlpEntry struct {
+	service string
+	record  OTelLogRecord
+}
+
+// OTLPExporter is a Sink that batches records and POSTs them as OTLP/HTTP JSON to a collector.
+// Records of failed exports are kept for the next ones and the failures of background exports
+// are reported to the write errors of the logger it was added to (see SetErrorHandler)
+type OTLPExporter struct {
+	opts    OTLPOptions
+	mu      sync.Mutex
+	pending []otlpEntry
+	err     error           // error of the last export
+	report  func(err error) // reports the failures of background exports, nil ignores them
+	flushCh chan struct{}
+	done    chan struct{}
+	wg      sync.WaitGroup
+	once    sync.Once
+}
+
+// NewOTLPExporter creates an exporter and starts its background flushing. Passing nil uses
+// all defaults. Register it with Logger.AddSink and release it with Logger.Close
+func NewOTLPExporter(opts *OTLPOptions) *OTLPExporter {
+	o := OTLPOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.Endpoint == "" {
+		o.Endpoint = DefaultOTLPEndpoint
+	}
+	if o.BatchSize <= 0 {
+		o.BatchSize = DefaultOTLPBatchSize
+	}
+	if o.FlushInterval <= 0 {
+		o.FlushInterval = DefaultOTLPFlushInterval
+	}
+	if o.MaxPending <= 0 {
+		o.MaxPending = DefaultOTLPMaxPending
+	}
+	if o.MaxPending < o.BatchSize {
+		o.MaxPending = o.BatchSize
+	}
+	if o.Client == nil {
+		o.Client = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	e := &OTLPExporter{
+		opts:    o,
+		flushCh: make(chan struct{}, 1),
+		done:    make(chan struct{}),
+	}
+	e.wg.Add(1)
+	go e.run()
+	return e
+}
+
+// run exports pending records every FlushInterval or whenever a batch fills up
+func (e *OTLPExporter) run() {
+	defer e.wg.Done()
+	ticker := time.NewTicker(e.opts.FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-e.flushCh:
+		case <-e.done:
+			return
+		}
+		if err := e.Flush(); err != nil {
+			e.mu.Lock()
+			report := e.report
+			e.mu.Unlock()
+			if report != nil {
+				report(err)
+			}
+		}
+	}
+}
+
+// reportErrors sets the report of the failures of background exports
+func (e *OTLPExporter) reportErrors(report func(err error)) {
+	e.mu.Lock()
+	e.report = report
+	e.mu.Unlock()
+}
+
+// Write queues the record for the next export. It fails with ErrOTLPQueueFull, without
+// queuing the record, when MaxPending records wait for the collector
+func (e *OTLPExporter) Write(info *Info) error {
+	entry := otlpEntry{service: info.Module, record: info.OTelRecord()}
+
+	e.mu.Lock()
+	if len(e.pending) >= e.opts.MaxPending {
+		err := e.err
+		e.mu.Unlock()
+		if err == nil {
+			return ErrOTLPQueueFull
+		}
+		return fmt.Errorf("%w: %v", ErrOTLPQueueFull, err)
+	}
+	e.pending = append(e.pending, entry)
+	full := len(e.pending) >= e.opts.BatchSize
+	e.mu.Unlock()
+
+	if full {
+		select {
+		case e.flushCh <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+// Flush synchronously exports all pending records. When the export fails the records are kept
+// for the next one, dropping the oldest beyond MaxPending, unless the collector rejected them
+func (e *OTLPExporter) Flush() error {
+	e.mu.Lock()
+	batch := e.pending
+	e.pending = nil
+	e.mu.Unlock()
+
+	if len(batch) == 0 {
+		return nil
+	}
+
+	retry, err := e.export(batch)
+	e.mu.Lock()
+	e.err = err
+	if retry {
+		e.pending = append(batch, e.pending...)
+		if over := len(e.pending) - e.opts.MaxPending; over > 0 {
+			e.pending = e.pending[over:]
+		}
+	}
+	e.mu.Unlock()
+	return err
+}
+
+// export POSTs a batch to the collector and reports if a failed export can be retried
+func (e *OTLPExporter) export(batch []otlpEntry) (bool, error) {
+	body, err := json.Marshal(newOTLPPayload(batch))
+	if err != nil {
+		return false, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, e.opts.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range e.opts.Headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := e.opts.Client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		// other client errors are rejected payloads, exporting them again would fail again
+		retry := resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusRequestTimeout
+		return retry, fmt.Errorf("otlp export to %s failed: %s", e.opts.Endpoint, resp.Status)
+	}
+	return false, nil
+}
+
+// Close stops background flushing and exports any remaining records
+func (e *OTLPExporter) Close() error {
+	e.once.Do(func() {
+		close(e.done)
+		e.wg.Wait()
+	})
+	return e.Flush()
+}
+
+// OTLP/HTTP JSON request envelope (ExportLogsServiceRequest)
+type (
+	otlpPayload struct {
+		ResourceLogs []otlpResourceLogs `json:"resourceLogs"`
+	}
+	otlpResourceLogs struct {
+		Resource  otlpResource    `json:"resource"`
+		ScopeLogs []otlpScopeLogs `json:"scopeLogs"`
+	}
+	otlpResource struct {
+		Attributes []OTelKeyValue `json:"attributes"`
+	}
+	otlpScopeLogs struct {
+		Scope      otlpScope       `json:"scope"`
+		LogRecords []OTelLogRecord `json:"logRecords"`
+	}
+	otlpScope struct {
+		Name string `json:"name"`
+	}
+)
+
+// newOTLPPayload groups the batch by service, keeping the order records were logged in
+func newOTLPPayload(batch []otlpEntry) *otlpPayload {
+	p := &otlpPayload{}
+	index := map[string]int{}
+	for _, entry := range batch {
+		i, ok := index[entry.service]
+		if !ok {
+			i = len(p.ResourceLogs)
+			index[entry.service] = i
+			p.ResourceLogs = append(p.ResourceLogs, otlpResourceLogs{
+				Resource: otlpResource{Attributes: []OTelKeyValue{
+					{Key: "service.name", Value: OTelAnyValue{entry.service}},
+				}},
+				ScopeLogs: []otlpScopeLogs{{Scope: otlpScope{Name: otlpScopeName}}},
+			})
+		}
+		scope := &p.ResourceLogs[i].ScopeLogs[0]
+		scope.LogRecords = append(scope.LogRecords, entry.record)
+	}
+	return p
+}
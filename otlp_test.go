@@ -0,0 +1,203 @@
+package golog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestOTelSeverity(t *testing.T) {
+	var tests = []struct {
+		level  LogLevel
+		number int
+		text   string
+	}{
+		{RawLevel, 0, "RAW"},
+		{TraceLevel, 15, "WARN3"},
+		{DebugLevel, 5, "DEBUG"},
+		{InfoLevel, 9, "INFO"},
+		{NoticeLevel, 10, "INFO2"},
+		{SuccessLevel, 11, "INFO3"},
+		{WarningLevel, 13, "WARN"},
+		{ErrorLevel, 17, "ERROR"},
+	}
+
+	for _, test := range tests {
+		number, text := OTelSeverity(test.level)
+		if number != test.number || text != test.text {
+			t.Errorf("Level %d: Want: %d %s Have: %d %s", test.level, test.number, test.text, number, text)
+		}
+	}
+}
+
+func TestOTLPExporter(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		payloads []map[string]interface{}
+	)
+
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ct := r.Header.Get("Content-Type"); ct != "application/json" {
+			t.Errorf("Unexpected content type: %s", ct)
+		}
+		if auth := r.Header.Get("Authorization"); auth != "Bearer token" {
+			t.Errorf("Unexpected authorization: %s", auth)
+		}
+		body, _ := io.ReadAll(r.Body)
+		var payload map[string]interface{}
+		if err := json.Unmarshal(body, &payload); err != nil {
+			t.Errorf("Invalid payload: %v", err)
+		}
+		mu.Lock()
+		payloads = append(payloads, payload)
+		mu.Unlock()
+	}))
+	defer collector.Close()
+
+	exporter := NewOTLPExporter(&OTLPOptions{
+		Endpoint: collector.URL,
+		Headers:  map[string]string{"Authorization": "Bearer token"},
+	})
+
+	var buf bytes.Buffer
+	log := NewLogger(&Options{Module: "otlp-service", Out: &buf})
+	log.SetEnvironment(EnvDevelopment)
+	log.AddSink(exporter)
+
+	ctx := ContextWithSpan(context.Background(), "4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7")
+	log.LogContext(ctx, WarningLevel, "correlated")
+	log.Info("plain")
+
+	if err := log.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(payloads) != 1 {
+		t.Fatalf("Want 1 export, Have %d", len(payloads))
+	}
+
+	resource := payloads[0]["resourceLogs"].([]interface{})[0].(map[string]interface{})
+	service := resource["resource"].(map[string]interface{})["attributes"].([]interface{})[0].(map[string]interface{})
+	if service["key"] != "service.name" || service["value"].(map[string]interface{})["stringValue"] != "otlp-service" {
+		t.Errorf("Unexpected resource attribute: %v", service)
+	}
+
+	records := resource["scopeLogs"].([]interface{})[0].(map[string]interface{})["logRecords"].([]interface{})
+	if len(records) != 2 {
+		t.Fatalf("Want 2 records, Have %d", len(records))
+	}
+
+	first := records[0].(map[string]interface{})
+	if first["severityText"] != "WARN" || first["severityNumber"].(float64) != 13 {
+		t.Errorf("Unexpected severity: %v %v", first["severityText"], first["severityNumber"])
+	}
+	if first["body"].(map[string]interface{})["stringValue"] != "correlated" {
+		t.Errorf("Unexpected body: %v", first["body"])
+	}
+	if first["traceId"] != "4bf92f3577b34da6a3ce929d0e0e4736" || first["spanId"] != "00f067aa0ba902b7" {
+		t.Errorf("Unexpected span: %v %v", first["traceId"], first["spanId"])
+	}
+	if _, ok := first["timeUnixNano"].(string); !ok {
+		t.Errorf("timeUnixNano must be encoded as a string: %v", first["timeUnixNano"])
+	}
+
+	second := records[1].(map[string]interface{})
+	if _, ok := second["traceId"]; ok {
+		t.Errorf("Unexpected traceId on uncorrelated record: %v", second["traceId"])
+	}
+}
+
+func TestOTLPExporterBatchSize(t *testing.T) {
+	exports := make(chan int, 4)
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload otlpPayload
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		exports <- len(payload.ResourceLogs[0].ScopeLogs[0].LogRecords)
+	}))
+	defer collector.Close()
+
+	exporter := NewOTLPExporter(&OTLPOptions{Endpoint: collector.URL, BatchSize: 2})
+	defer exporter.Close()
+
+	_ = exporter.Write(&Info{Module: "batch", Level: InfoLevel, Message: "one"})
+	_ = exporter.Write(&Info{Module: "batch", Level: InfoLevel, Message: "two"})
+
+	if n := <-exports; n != 2 {
+		t.Errorf("Want batch of 2, Have %d", n)
+	}
+}
+
+func TestOTLPExporterError(t *testing.T) {
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer collector.Close()
+
+	exporter := NewOTLPExporter(&OTLPOptions{Endpoint: collector.URL})
+	_ = exporter.Write(&Info{Module: "failing", Level: ErrorLevel, Message: "lost"})
+	if err := exporter.Close(); err == nil {
+		t.Error("Expected error from failing collector")
+	}
+}
+
+func TestOTLPExporterOutage(t *testing.T) {
+	var down int32 = 1
+	exports := make(chan int, 4)
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&down) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		var payload otlpPayload
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		exports <- len(payload.ResourceLogs[0].ScopeLogs[0].LogRecords)
+	}))
+	defer collector.Close()
+
+	failures := make(chan error, 4)
+	exporter := NewOTLPExporter(&OTLPOptions{Endpoint: collector.URL, BatchSize: 2, MaxPending: 2})
+	log := NewLogger(&Options{
+		Module:       "otlp-outage",
+		Out:          io.Discard,
+		IDs:          IDPerLogger,
+		ErrorHandler: func(err error) { failures <- err },
+	})
+	log.SetEnvironment(EnvDevelopment)
+	log.AddSink(exporter)
+
+	log.Info("one")
+	log.Info("two")
+	var werr *WriteError
+	if err := <-failures; !errors.As(err, &werr) || werr.Sink != "*golog.OTLPExporter" || !strings.Contains(err.Error(), "503") {
+		t.Errorf("Want the failed background export reported Have: %v", err)
+	}
+
+	log.Info("three")
+	if err := <-failures; !errors.Is(err, ErrOTLPQueueFull) {
+		t.Errorf("Want: %v Have: %v", ErrOTLPQueueFull, err)
+	}
+	if log.ErrorCount() != 2 {
+		t.Errorf("Want: 2 Have: %d", log.ErrorCount())
+	}
+
+	atomic.StoreInt32(&down, 0)
+	if err := exporter.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if n := <-exports; n != 2 {
+		t.Errorf("Want the 2 kept records exported Have: %d", n)
+	}
+	if err := log.Close(); err != nil {
+		t.Error(err)
+	}
+}
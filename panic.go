@@ -0,0 +1,137 @@
+// Package golog Simple flexible go logging
+// This file contains the recovery & logging of panics
+package golog
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"path"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// Frame is a function call of a stack
+type Frame struct {
+	Function string
+	File     string
+	Line     int
+}
+
+// String returns the function, file & line of the frame
+func (f Frame) String() string {
+	return f.Function + " " + f.File + ":" + strconv.Itoa(f.Line)
+}
+
+// Frames is a parsed stack, innermost call first. It is logged as an array of "function file:line"
+type Frames []Frame
+
+// MarshalLogArray appends the frames as strings
+func (fs Frames) MarshalLogArray(enc ArrayEncoder) error {
+	for _, f := range fs {
+		enc.AppendString(f.String())
+	}
+	return nil
+}
+
+// String returns the frames one per line
+func (fs Frames) String() string {
+	var sb strings.Builder
+	for i, f := range fs {
+		if i > 0 {
+			sb.WriteByte('\n')
+		}
+		sb.WriteString(f.String())
+	}
+	return sb.String()
+}
+
+// PanicFrames returns the stack of a panicking goroutine from the call to panic, when called by
+// a deferred function. Frames of the runtime & of the deferred calls are skipped
+func PanicFrames() Frames {
+	var pcs [64]uintptr
+	n := runtime.Callers(2, pcs[:]) // skip Callers & PanicFrames
+	frames := runtime.CallersFrames(pcs[:n])
+
+	var all Frames
+	start := 0
+	for {
+		frame, more := frames.Next()
+		if frame.Function == "runtime.gopanic" {
+			start = len(all) + 1
+		}
+		all = append(all, Frame{Function: frame.Function, File: frame.File, Line: frame.Line})
+		if !more {
+			break
+		}
+	}
+
+	// runtime errors panic through the runtime, like runtime.panicmem & runtime.sigpanic
+	for start < len(all) && strings.HasPrefix(all[start].Function, "runtime.") {
+		start++
+	}
+	end := len(all)
+	if end > start && all[end-1].Function == "runtime.goexit" {
+		end--
+	}
+	return all[start:end]
+}
+
+// LogPanic logs a value recovered from a panic at Error level with the stack of the panic as
+// the "stack" field, attributed to the call to panic. It must be called by the deferred
+// function calling recover
+func (l *Logger) LogPanic(ctx context.Context, value interface{}) {
+	if !l.worker.enabled(ErrorLevel, 0, l.Options.Module) {
+		return
+	}
+	frames := PanicFrames()
+	info := l.newInfo(ctx, ErrorLevel, 2, fmt.Sprintf("panic: %v", value))
+	if len(frames) > 0 {
+		f := frames[0]
+		info.pc, info.resolved = 0, true
+		info.Function, info.Filename, info.Path, info.Line = f.Function, path.Base(f.File), f.File, f.Line
+	}
+	info.Fields = append(append(make([]Field, 0, len(l.fields)+1), l.fields...), Array("stack", frames))
+	l.write(info)
+}
+
+// Recover wraps next so a panic of a handler is logged with LogPanic and answered with a 500
+// Internal Server Error, unless the handler already wrote a response. http.ErrAbortHandler
+// is passed on to the server, which aborts the response silently
+func (l *Logger) Recover(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		defer func() {
+			v := recover()
+			if v == nil {
+				return
+			}
+			if v == http.ErrAbortHandler {
+				panic(v)
+			}
+			l.LogPanic(RequestContext(r), v)
+			if !rec.wrote {
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			}
+		}()
+		next.ServeHTTP(rec, r)
+	})
+}
+
+// Go runs f in a new goroutine, logging a panic of f with LogPanic instead of crashing the program
+func (l *Logger) Go(f func()) {
+	go func() {
+		defer func() {
+			if v := recover(); v != nil {
+				l.LogPanic(nil, v)
+			}
+		}()
+		f()
+	}()
+}
+
+// Go runs f in a new goroutine, logging a panic of f to the default logger (see Logger.Go)
+func Go(f func()) {
+	Log.Go(f)
+}
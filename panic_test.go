@@ -0,0 +1,110 @@
+package golog
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// lineSink sends the caller, message & innermost stack frame of the records it receives
+type lineSink chan string
+
+func (s lineSink) Write(info *Info) error {
+	stack, _ := info.Field("stack")
+	frames, _ := stack.(Frames)
+	s <- fmt.Sprintf("%s:%d %s %v", info.Filename, info.Line, info.Message, frames)
+	return nil
+}
+
+func newPanicLogger(buf *bytes.Buffer) *Logger {
+	l := NewLogger(&Options{Module: "panic", Out: buf, Levels: LevelOverrides{}})
+	l.SetEnvironment(EnvDevelopment)
+	_ = l.SetFormat("%{level} %{file}:%{line} %{message} %{fields}")
+	return l
+}
+
+func TestRecover(t *testing.T) {
+	var buf bytes.Buffer
+	l := newPanicLogger(&buf)
+	h := l.Recover(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var m map[string]int
+		m["boom"]++
+	}))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/boom", nil))
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("Want: 500 Have: %d", rec.Code)
+	}
+	want := "ERROR panic_test.go:35 panic: assignment to entry in nil map stack=[\"golog.TestRecover.func1 "
+	if have := strings.Replace(buf.String(), "github.com/AndrewDonelson/", "", -1); !strings.HasPrefix(have, want) {
+		t.Errorf("\nWant: %q\nHave: %q", want, have)
+	}
+}
+
+func TestRecoverWritten(t *testing.T) {
+	var buf bytes.Buffer
+	h := newPanicLogger(&buf).Recover(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+		panic("late")
+	}))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	if rec.Code != http.StatusAccepted || !strings.Contains(buf.String(), "panic: late") {
+		t.Errorf("Want the 202 response kept & the panic logged Have: %d %q", rec.Code, buf.String())
+	}
+
+	defer func() {
+		if v := recover(); v != http.ErrAbortHandler {
+			t.Errorf("Want: http.ErrAbortHandler Have: %v", v)
+		}
+	}()
+	buf.Reset()
+	h = newPanicLogger(&buf).Recover(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	}))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+}
+
+func TestGo(t *testing.T) {
+	l := newPanicLogger(&bytes.Buffer{})
+	lines := make(lineSink, 1)
+	l.AddSink(lines)
+	l.Go(func() {
+		panic("background")
+	})
+	line := <-lines
+
+	want := "panic_test.go:78 panic: background github.com/AndrewDonelson/golog.TestGo.func1 "
+	if have := line; !strings.HasPrefix(have, want) {
+		t.Errorf("\nWant: %q\nHave: %q", want, have)
+	}
+	if strings.Contains(line, "runtime.") {
+		t.Errorf("Want the runtime frames skipped Have: %q", line)
+	}
+}
+
+func TestRecoverStreaming(t *testing.T) {
+	var buf bytes.Buffer
+	h := newPanicLogger(&buf).Recover(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := w.(http.Flusher); !ok {
+			t.Error("Want an http.Flusher")
+		}
+		if err := http.NewResponseController(w).Flush(); err != nil {
+			t.Errorf("Flush: %v", err)
+		}
+		if _, _, err := w.(http.Hijacker).Hijack(); !errors.Is(err, http.ErrNotSupported) {
+			t.Errorf("Want: %v Have: %v", http.ErrNotSupported, err)
+		}
+		panic("streamed")
+	}))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/events", nil))
+	if !rec.Flushed || rec.Code != http.StatusOK {
+		t.Errorf("Want the flushed 200 response kept Have: %d flushed %v", rec.Code, rec.Flushed)
+	}
+}
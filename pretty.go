@@ -0,0 +1,193 @@
+// Package golog Simple flexible go logging
+// This file contains the pretty console encoder for development
+package golog
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	// prettyIndent is the indent of the gutter of continuation lines, the width of the time column
+	prettyIndent = "          "
+
+	// prettyGutter starts the continuation lines of a record
+	prettyGutter = "│ "
+
+	// Styles used by the console encoder without a theme
+	prettyDim      = "\033[2m"
+	prettyLocation = "\033[2;4m"
+	prettyReset    = "\033[0m"
+)
+
+// workDir is the directory source locations are shown relative to
+var workDir, _ = os.Getwd()
+
+// ConsoleOptions customize the pretty console encoder
+type ConsoleOptions struct {
+	ModuleWidth int // width of the module column, defaults to 12
+}
+
+// consoleRenderer returns a renderer writing records for humans: aligned columns of
+// elapsed time, level & module, followed by the message and the source location. Message
+// lines after the first, and the fields as key=value, follow on lines starting with a gutter
+func consoleRenderer(opts ConsoleOptions) *renderer {
+	width := opts.ModuleWidth
+	if width <= 0 {
+		width = 12
+	}
+	op := func(buf []byte, r *Info, pal *palette) []byte {
+		return appendPretty(buf, r, pal, width)
+	}
+	return &renderer{ops: []renderOp{op}, elems: []element{noElement}, colorArgs: true}
+}
+
+// prettyStyle returns the escape sequence of an element: the theme's one if the palette has
+// a theme, otherwise def. No style is used without a palette
+func prettyStyle(pal *palette, e element, level LogLevel, def string) string {
+	switch {
+	case pal == nil:
+		return ""
+	case pal.themed:
+		return pal.element(e, level)
+	}
+	return def
+}
+
+// appendStyled appends text in the style
+func appendStyled(buf []byte, style, text string) []byte {
+	if style == "" {
+		return append(buf, text...)
+	}
+	buf = append(buf, style...)
+	buf = append(buf, text...)
+	return append(buf, prettyReset...)
+}
+
+// appendGutter starts a continuation line
+func appendGutter(buf []byte, pal *palette) []byte {
+	buf = append(buf, '\n')
+	buf = append(buf, prettyIndent...)
+	return appendStyled(buf, prettyStyle(pal, noElement, 0, prettyDim), prettyGutter)
+}
+
+// appendPretty appends the record in the console layout
+func appendPretty(buf []byte, r *Info, pal *palette, moduleWidth int) []byte {
+	// relative time, right aligned
+	var col [32]byte
+	elapsed := append(col[:0], '+')
+	elapsed = strconv.AppendFloat(elapsed, r.Elapsed.Seconds(), 'f', 3, 64)
+	elapsed = appendPadded(append(elapsed, 's'), 0, verbSpec{width: len(prettyIndent) - 1})
+	buf = appendStyled(buf, prettyStyle(pal, timeElement, r.Level, prettyDim), string(elapsed))
+	buf = append(buf, ' ')
+
+	level := appendString(col[:0], r.logLevelString(), verbSpec{left: true, width: 7, prec: -1})
+	buf = appendStyled(buf, prettyStyle(pal, levelElement, r.Level, pal.levelOrNone(r.Level)), string(level))
+	buf = append(buf, ' ')
+
+	module := appendString(nil, r.Module, verbSpec{left: true, width: moduleWidth, prec: moduleWidth})
+	buf = appendStyled(buf, prettyStyle(pal, moduleElement, r.Level, ""), string(module))
+	buf = append(buf, ' ')
+
+	message, rest := r.Message, ""
+	if i := strings.IndexByte(message, '\n'); i >= 0 {
+		message, rest = message[:i], strings.TrimRight(message[i+1:], "\n\x00")
+	}
+	buf = appendStyled(buf, prettyStyle(pal, messageElement, r.Level, ""), message)
+	buf = appendLocation(buf, r, pal)
+
+	if rest != "" {
+		for _, line := range strings.Split(rest, "\n") {
+			buf = append(appendGutter(buf, pal), line...)
+		}
+	}
+	for i := range r.Fields {
+		buf = appendPrettyField(buf, &r.Fields[i], pal)
+	}
+	return buf
+}
+
+// levelOrNone returns the escape sequence of a level, none without a palette
+func (p *palette) levelOrNone(level LogLevel) string {
+	if p == nil {
+		return ""
+	}
+	return p.level(level)
+}
+
+// appendLocation appends the source location as a path:line that terminals & editors can
+// open, and as a hyperlink on colored outputs
+func appendLocation(buf []byte, r *Info, pal *palette) []byte {
+	r.resolveCaller()
+	file := r.Filename
+	if r.Path != "" {
+		file = r.Path
+		if rel, err := filepath.Rel(workDir, r.Path); err == nil && !strings.HasPrefix(rel, "..") {
+			file = rel
+		}
+	}
+	if file == "" {
+		return buf
+	}
+	location := file + ":" + strconv.Itoa(r.Line)
+
+	buf = append(buf, "  "...)
+	if pal == nil || r.Path == "" {
+		return append(buf, location...)
+	}
+	// OSC 8 hyperlink to the file
+	buf = append(buf, "\033]8;;file://"...)
+	buf = append(buf, filepath.ToSlash(r.Path)...)
+	buf = append(buf, "\033\\"...)
+	buf = appendStyled(buf, prettyStyle(pal, callerElement, r.Level, prettyLocation), location)
+	return append(buf, "\033]8;;\033\\"...)
+}
+
+// appendPrettyField appends a field as a key=value continuation line, multi-line strings &
+// errors such as stack traces are indented on their own lines
+func appendPrettyField(buf []byte, f *Field, pal *palette) []byte {
+	buf = appendGutter(buf, pal)
+	var text string
+	switch f.kind {
+	case stringField:
+		text = f.str
+	case errorField:
+		if err, ok := f.Value.(error); ok && err != nil {
+			text = err.Error()
+		}
+	}
+	if !strings.Contains(text, "\n") {
+		enc := textEncoder{buf: buf, first: true}
+		enc.key(f.Key)
+		f.appendText(&enc)
+		return enc.buf
+	}
+
+	buf = append(append(buf, f.Key...), ':')
+	for _, line := range strings.Split(strings.TrimRight(text, "\n"), "\n") {
+		buf = append(append(appendGutter(buf, pal), "  "...), line...)
+	}
+	return buf
+}
+
+// UsePrettyConsole writes records with the pretty console encoder, until the format or the
+// environment is changed. Passing nil uses the default options
+func (w *Worker) UsePrettyConsole(opts *ConsoleOptions) {
+	o := ConsoleOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	w.update(func(c *workerConfig) {
+		c.format, c.goroutine = "", false
+		c.renderer = consoleRenderer(o)
+	})
+}
+
+// UsePrettyForDevelopment forces using the pretty console encoder for development
+func (w *Worker) UsePrettyForDevelopment() {
+	if w.config().environment == EnvDevelopment {
+		w.UsePrettyConsole(nil)
+	}
+}
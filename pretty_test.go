@@ -0,0 +1,59 @@
+package golog
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestPrettyConsole(t *testing.T) {
+	var buf bytes.Buffer
+	log := NewLogger(&Options{Module: "billing", Out: &buf})
+	log.SetEnvironment(EnvDevelopment)
+	log.SetColor(ClrDisabled)
+	log.UsePrettyForDevelopment()
+
+	log.With(String("user", "alice"), Int("n", 42), Err(errors.New("first\nsecond"))).Warning("charged\ntwice")
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 7 {
+		t.Fatalf("Want 7 lines Have %d: %q", len(lines), buf.String())
+	}
+	if !strings.HasPrefix(lines[0], "  +0.") || !strings.Contains(lines[0], "s WARNING billing      charged  pretty_test.go:17") {
+		t.Errorf("Unexpected first line %q", lines[0])
+	}
+	want := []string{
+		"          │ twice",
+		"          │ user=alice",
+		"          │ n=42",
+		"          │ error:",
+		"          │   first",
+		"          │   second",
+	}
+	for i, line := range want {
+		if lines[i+1] != line {
+			t.Errorf("\nWant: %q\nHave: %q", line, lines[i+1])
+		}
+	}
+	buf.Reset()
+
+	log.UsePrettyConsole(&ConsoleOptions{ModuleWidth: 4})
+	log.SetColor(ClrEnabled)
+	log.Error("colored")
+	have := buf.String()
+	for _, want := range []string{"\033[2m", colors[ErrorLevel] + "ERROR  \033[0m bill colored", "\033]8;;file://", "pretty_test.go:"} {
+		if !strings.Contains(have, want) {
+			t.Errorf("%q does not contain %q", have, want)
+		}
+	}
+	buf.Reset()
+
+	// other environments keep their format
+	log.SetEnvironment(EnvProduction)
+	log.UsePrettyForDevelopment()
+	log.SetColor(ClrDisabled)
+	log.Error("production")
+	if have := buf.String(); !strings.HasPrefix(have, "[") {
+		t.Errorf("Unexpected production output %q", have)
+	}
+}
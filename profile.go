@@ -0,0 +1,207 @@
+// Package golog Simple flexible go logging
+// This file contains the registry of environment profiles
+package golog
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Profile bundles the settings applied by Logger.SetEnvironment. Besides the built-in
+// development, quality & production profiles, profiles can be registered in code with
+// RegisterProfile or from a JSON config with LoadProfiles, and are selected by name with
+// SetEnvironmentFromString or the BUILD_ENV environment variable
+type Profile struct {
+	Name      string    // e.g. "staging", matched case insensitively
+	Aliases   []string  // other names selecting the profile, e.g. "stage"
+	Level     LogLevel  // most verbose level written
+	Verbosity int       // highest V(n) written
+	Format    string    // placeholder or printf format, defaults to FmtDefault
+	Color     ColorMode // zero value disables color
+	Theme     string    // name of a registered theme, empty keeps the theme of the logger
+	Sinks     []Sink    // receive the records of every logger using the profile, owned by the caller
+	Sampling  *Sampling // nil writes every record
+}
+
+// profileRegistry holds the profiles, indexed by their Environment
+type profileRegistry struct {
+	mu       sync.RWMutex
+	profiles []*Profile // EnvAuto has no profile
+	names    map[string]Environment
+}
+
+// profiles is the registry of all known profiles
+var profiles = newProfileRegistry()
+
+// newProfileRegistry returns a registry holding the built-in profiles
+func newProfileRegistry() *profileRegistry {
+	r := &profileRegistry{profiles: []*Profile{nil}, names: map[string]Environment{}}
+	for _, p := range []Profile{
+		{Name: "development", Aliases: []string{"dev"}, Level: DebugLevel, Verbosity: 1, Format: FmtDevelopmentLog, Color: ClrAuto},
+		{Name: "quality", Aliases: []string{"qa"}, Level: InfoLevel, Format: FmtProductionLog, Color: ClrAuto},
+		{Name: "production", Aliases: []string{"prod"}, Level: SuccessLevel, Format: FmtProductionLog, Color: ClrAuto},
+	} {
+		_, _ = r.register(p)
+	}
+	return r
+}
+
+// register adds the profile, or replaces the profile already registered under its name
+func (r *profileRegistry) register(p Profile) (Environment, error) {
+	name := strings.ToLower(strings.TrimSpace(p.Name))
+	if name == "" {
+		return EnvAuto, fmt.Errorf("golog: profile has no name")
+	}
+	if p.Level < RawLevel || p.Level > DebugLevel {
+		return EnvAuto, fmt.Errorf("golog: profile %q has an invalid level %d", name, p.Level)
+	}
+	if strings.Contains(p.Format, "%{") {
+		if _, err := parseFormat(p.Format); err != nil {
+			return EnvAuto, fmt.Errorf("golog: profile %q: %v", name, err)
+		}
+	}
+	if _, ok := LookupTheme(p.Theme); p.Theme != "" && !ok {
+		return EnvAuto, fmt.Errorf("golog: profile %q has an unknown theme %q", name, p.Theme)
+	}
+	p.Name = name
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	env, ok := r.names[name]
+	if ok {
+		r.profiles[env] = &p
+	} else {
+		env = Environment(len(r.profiles))
+		r.profiles = append(r.profiles, &p)
+	}
+	r.names[name] = env
+	for _, alias := range p.Aliases {
+		r.names[strings.ToLower(strings.TrimSpace(alias))] = env
+	}
+	return env, nil
+}
+
+// lookup returns the environment of a profile name or alias
+func (r *profileRegistry) lookup(name string) (Environment, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	env, ok := r.names[strings.ToLower(strings.TrimSpace(name))]
+	return env, ok
+}
+
+// profile returns the profile of an environment
+func (r *profileRegistry) profile(env Environment) (*Profile, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if env <= EnvAuto || int(env) >= len(r.profiles) {
+		return nil, false
+	}
+	return r.profiles[env], true
+}
+
+// RegisterProfile registers a profile and returns the Environment selecting it. Registering
+// a name that is already known (including "development", "quality" & "production")
+// replaces that profile, loggers pick up the change the next time their environment is set
+func RegisterProfile(p Profile) (Environment, error) {
+	return profiles.register(p)
+}
+
+// LookupProfile returns the environment of a profile name or alias
+func LookupProfile(name string) (Environment, bool) {
+	return profiles.lookup(name)
+}
+
+// GetProfile returns the profile of an environment
+func GetProfile(env Environment) (Profile, bool) {
+	p, ok := profiles.profile(env)
+	if !ok {
+		return Profile{}, false
+	}
+	return *p, true
+}
+
+// profileConfig is the JSON form of a Profile
+type profileConfig struct {
+	Name      string   `json:"name"`
+	Aliases   []string `json:"aliases"`
+	Level     string   `json:"level"`
+	Verbosity int      `json:"verbosity"`
+	Format    string   `json:"format"`
+	Color     string   `json:"color"`
+	Theme     string   `json:"theme"`
+	Sampling  *struct {
+		Initial    int    `json:"initial"`
+		Thereafter int    `json:"thereafter"`
+		Tick       string `json:"tick"`
+	} `json:"sampling"`
+}
+
+// LoadProfiles registers the profiles of a JSON config holding a list of profiles:
+//
+//	[{"name": "staging", "aliases": ["stage"], "level": "info", "format": "%{time} %{level} %{message}",
+//	  "color": "disabled", "sampling": {"initial": 100, "thereafter": 10, "tick": "1s"}}]
+//
+// Colors are "auto", "enabled" or "disabled" (the default) and themes are registered names
+// (see LoadThemes). Sinks can only be set in code
+func LoadProfiles(r io.Reader) error {
+	var configs []profileConfig
+	if err := json.NewDecoder(r).Decode(&configs); err != nil {
+		return fmt.Errorf("golog: invalid profiles config: %v", err)
+	}
+
+	for _, c := range configs {
+		p := Profile{Name: c.Name, Aliases: c.Aliases, Verbosity: c.Verbosity, Format: c.Format, Theme: c.Theme}
+		var err error
+		if p.Level, err = ParseLogLevel(c.Level); err != nil {
+			return fmt.Errorf("golog: profile %q: %v", c.Name, err)
+		}
+		switch strings.ToLower(c.Color) {
+		case "", "disabled", "off":
+			p.Color = ClrDisabled
+		case "enabled", "on":
+			p.Color = ClrEnabled
+		case "auto":
+			p.Color = ClrAuto
+		default:
+			return fmt.Errorf("golog: profile %q: unknown color mode %q", c.Name, c.Color)
+		}
+		if s := c.Sampling; s != nil {
+			p.Sampling = &Sampling{Initial: s.Initial, Thereafter: s.Thereafter}
+			if s.Tick != "" {
+				if p.Sampling.Tick, err = time.ParseDuration(s.Tick); err != nil {
+					return fmt.Errorf("golog: profile %q: %v", c.Name, err)
+				}
+			}
+		}
+		if _, err := RegisterProfile(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyProfile sets the level, format, color, theme, sinks & sampling of the profile
+func (c *workerConfig) applyProfile(p *Profile) {
+	c.level = p.Level
+	c.verbosity = p.Verbosity
+	switch {
+	case strings.Contains(p.Format, "%{"):
+		if c.setFormat(p.Format) != nil {
+			c.setPrintfFormat(defFmt)
+		}
+	case p.Format == "":
+		c.setPrintfFormat(defFmt)
+	default:
+		c.setPrintfFormat(p.Format)
+	}
+	c.color = p.Color
+	if theme, ok := LookupTheme(p.Theme); ok {
+		_ = c.setTheme(theme)
+	}
+	c.profileSinks = p.Sinks
+	c.sampler = newSampler(p.Sampling)
+}
@@ -0,0 +1,141 @@
+package golog
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+// messageSink records the messages of the records it receives
+type messageSink struct {
+	messages []string
+}
+
+func (s *messageSink) Write(info *Info) error {
+	s.messages = append(s.messages, info.Message)
+	return nil
+}
+
+func TestRegisterProfile(t *testing.T) {
+	sink := &messageSink{}
+	env, err := RegisterProfile(Profile{
+		Name:    "Staging",
+		Aliases: []string{"stage"},
+		Level:   NoticeLevel,
+		Format:  "%{lvl} %{message}",
+		Sinks:   []Sink{sink},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if env <= EnvProduction {
+		t.Errorf("Unexpected environment %d", env)
+	}
+	if e, ok := LookupProfile("STAGE"); !ok || e != env {
+		t.Errorf("Alias not registered")
+	}
+	if p, ok := GetProfile(env); !ok || p.Name != "staging" {
+		t.Errorf("Unexpected profile %v", p)
+	}
+
+	t.Setenv("BUILD_ENV", "stage")
+	opts := NewDefaultOptions()
+	if opts.Environment != env || opts.EnvAsString() != "EnvStaging" {
+		t.Errorf("BUILD_ENV selected %s", opts.EnvAsString())
+	}
+
+	if o := NewCustomOptions("staging", env, ClrNotSet, false, nil, "", ""); o.Environment != env {
+		t.Errorf("NewCustomOptions selected %s", o.EnvAsString())
+	}
+
+	var buf bytes.Buffer
+	opts.Out = &buf
+	log := NewLogger(opts)
+	log.SetEnvironmentFromString("staging")
+	log.Notice("notice")
+	log.Info("hidden")
+	if have := buf.String(); have != "NOT notice\n" {
+		t.Errorf("Unexpected output %q", have)
+	}
+	if len(sink.messages) != 1 || sink.messages[0] != "notice" {
+		t.Errorf("Unexpected sink messages %v", sink.messages)
+	}
+
+	// re-registering replaces the profile
+	if e, _ := RegisterProfile(Profile{Name: "staging", Level: InfoLevel}); e != env {
+		t.Errorf("Want environment %d Have %d", env, e)
+	}
+	log.SetEnvironmentFromString("staging")
+	log.Info("info")
+	if len(sink.messages) != 1 || !strings.Contains(buf.String(), "info") {
+		t.Errorf("Profile not replaced")
+	}
+
+	log.SetEnvironmentFromString("nonexistent")
+	if log.Options.Environment != EnvProduction {
+		t.Errorf("Unknown profile selected %s", log.Options.EnvAsString())
+	}
+
+	for _, p := range []Profile{{Level: InfoLevel}, {Name: "x", Level: 42}, {Name: "x", Level: InfoLevel, Format: "%{nonexistent}"}} {
+		if _, err := RegisterProfile(p); err == nil {
+			t.Errorf("%v: expected an error", p)
+		}
+	}
+}
+
+func TestLoadProfiles(t *testing.T) {
+	config := `[{"name": "ci", "level": "warn", "format": "%{level} %{message}", "color": "auto",
+		"sampling": {"initial": 2, "thereafter": 3, "tick": "1m"}}]`
+	if err := LoadProfiles(strings.NewReader(config)); err != nil {
+		t.Fatal(err)
+	}
+	env, _ := LookupProfile("ci")
+	p, _ := GetProfile(env)
+	if p.Level != WarningLevel || p.Color != ClrAuto || p.Sampling == nil || p.Sampling.Tick != time.Minute {
+		t.Errorf("Unexpected profile %+v", p)
+	}
+
+	for _, config := range []string{
+		`{}`,
+		`[{"name": "ci2", "level": "loud"}]`,
+		`[{"name": "ci2", "level": "info", "color": "pink"}]`,
+		`[{"name": "ci2", "level": "info", "sampling": {"tick": "soon"}}]`,
+	} {
+		if err := LoadProfiles(strings.NewReader(config)); err == nil {
+			t.Errorf("%s: expected an error", config)
+		}
+	}
+}
+
+func TestSampling(t *testing.T) {
+	env, err := RegisterProfile(Profile{
+		Name:     "sampled",
+		Level:    DebugLevel,
+		Format:   "%{message}",
+		Sampling: &Sampling{Initial: 2, Thereafter: 3, Tick: time.Hour},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	log := NewLogger(&Options{Module: "sampling", Out: &buf})
+	log.SetEnvironment(env)
+	for i := 0; i < 10; i++ {
+		log.Info("repeated")
+		log.Debug("other")
+	}
+	log.Log(RawLevel, "raw")
+
+	// 1st, 2nd, 5th & 8th of each message
+	if have := strings.Count(buf.String(), "repeated\n"); have != 4 {
+		t.Errorf("Want 4 repeated records Have %d", have)
+	}
+	if have := strings.Count(buf.String(), "other\n"); have != 4 {
+		t.Errorf("Want 4 other records Have %d", have)
+	}
+	if !strings.Contains(buf.String(), "raw\n") {
+		t.Errorf("Raw record sampled")
+	}
+}
@@ -0,0 +1,411 @@
+// Package golog Simple flexible go logging
+// This file contains the compiled format renderer
+package golog
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"unicode/utf8"
+)
+
+// renderOp appends one literal or field of a record to buf
+type renderOp func(buf []byte, r *Info, pal *palette) []byte
+
+// renderer is a format compiled into a sequence of append operations, so records are
+// rendered without fmt.Sprintf and without boxing the fields of Info
+type renderer struct {
+	ops       []renderOp
+	elems     []element // element rendered by each op, styled by themes
+	colorArgs bool      // format colors placeholders itself (%{level:color})
+}
+
+// verbSpec is a parsed printf verb of the form %[-][0][width][.prec][index]conv
+type verbSpec struct {
+	left  bool
+	zero  bool
+	width int
+	prec  int // -1 when not set
+	index int
+}
+
+var (
+	// Buffers records are rendered into
+	bufPool = sync.Pool{New: func() interface{} {
+		b := make([]byte, 0, 256)
+		return &b
+	}}
+
+	// Renderers of printf formats passed to Info.Output
+	renderers sync.Map
+)
+
+// compileFormat compiles a parsed format. It returns nil if msgfmt uses printf verbs other
+// than the indexed %d, %s and %v verbs produced by parseFormat & used by the built-in formats
+func compileFormat(spec formatSpec) *renderer {
+	rd := &renderer{colorArgs: spec.colorArgs}
+	timefmt := spec.timefmt
+	if timefmt == "" {
+		timefmt = defTimeFmt
+	}
+	format := spec.msgfmt
+	lit := make([]byte, 0, len(format))
+	for len(format) > 0 {
+		idx := 0
+		for idx < len(format) && format[idx] != '%' {
+			idx++
+		}
+		lit = append(lit, format[:idx]...)
+		format = format[idx:]
+		if len(format) == 0 {
+			break
+		}
+		if len(format) > 1 && format[1] == '%' {
+			lit = append(lit, '%')
+			format = format[2:]
+			continue
+		}
+
+		vs, n, ok := parseVerb(format)
+		if !ok {
+			return nil
+		}
+		format = format[n:]
+
+		var op renderOp
+		elem := elementOf(vs.index)
+		switch {
+		case vs.index == 2:
+			op = timeOp(vs, timefmt)
+		case vs.index >= 1 && vs.index <= outputArgs:
+			op = valueOp(vs)
+		case vs.index > outputArgs && vs.index <= outputArgs+len(spec.args):
+			fa := &spec.args[vs.index-outputArgs-1]
+			if op = fa.compile(); op == nil {
+				return nil
+			}
+			if elem = noElement; !fa.color && !fa.json {
+				elem = elementOf(fa.index)
+			}
+		default:
+			return nil
+		}
+
+		if len(lit) > 0 {
+			rd.add(literalOp(string(lit)), noElement)
+			lit = lit[:0]
+		}
+		rd.add(op, elem)
+	}
+	if len(lit) > 0 {
+		rd.add(literalOp(string(lit)), noElement)
+	}
+	return rd
+}
+
+// add appends an operation rendering an element
+func (rd *renderer) add(op renderOp, elem element) {
+	rd.ops = append(rd.ops, op)
+	rd.elems = append(rd.elems, elem)
+}
+
+// sprintfRenderer renders a printf format compileFormat does not support with fmt.Sprintf
+func sprintfRenderer(format string) *renderer {
+	op := func(buf []byte, r *Info, pal *palette) []byte {
+		return append(buf, r.sprintf(format)...)
+	}
+	return &renderer{ops: []renderOp{op}, elems: []element{noElement}}
+}
+
+// parseVerb parses the printf verb at the start of format, returning its length
+func parseVerb(format string) (vs verbSpec, n int, ok bool) {
+	vs.prec = -1
+	n = 1
+	for ; n < len(format) && (format[n] == '-' || format[n] == '0'); n++ {
+		if format[n] == '-' {
+			vs.left = true
+		} else {
+			vs.zero = true
+		}
+	}
+	vs.width, n = parseNumber(format, n)
+	if n < len(format) && format[n] == '.' {
+		vs.prec, n = parseNumber(format, n+1)
+	}
+	if n >= len(format) || format[n] != '[' {
+		return vs, 0, false
+	}
+	vs.index, n = parseNumber(format, n+1)
+	if n+1 >= len(format) || format[n] != ']' {
+		return vs, 0, false
+	}
+	switch format[n+1] {
+	case 'd', 's', 'v':
+		return vs, n + 2, true
+	}
+	return vs, 0, false
+}
+
+// parseNumber parses the decimal digits of s starting at i, returning the index after them
+func parseNumber(s string, i int) (num, end int) {
+	for end = i; end < len(s) && s[end] >= '0' && s[end] <= '9'; end++ {
+		num = num*10 + int(s[end]-'0')
+	}
+	return
+}
+
+// literalOp appends text that is the same for every record
+func literalOp(text string) renderOp {
+	return func(buf []byte, r *Info, pal *palette) []byte {
+		return append(buf, text...)
+	}
+}
+
+// valueOp appends the Info.Output argument of the verb, formatted like printf would
+func valueOp(vs verbSpec) renderOp {
+	return func(buf []byte, r *Info, pal *palette) []byte {
+		return appendValue(buf, r, vs)
+	}
+}
+
+// timeOp appends the formatted time of the record, formatting its Timestamp with the
+// layout of the format unless the record was created with a preformatted Time
+func timeOp(vs verbSpec, timefmt string) renderOp {
+	return func(buf []byte, r *Info, pal *palette) []byte {
+		if r.Time != "" || r.Timestamp.IsZero() {
+			return appendString(buf, r.Time, vs)
+		}
+		start := len(buf)
+		return finishString(r.Timestamp.AppendFormat(buf, timefmt), start, vs)
+	}
+}
+
+// compile returns the operation rendering the placeholder argument
+func (fa *formatArg) compile() renderOp {
+	vs, _, ok := parseVerb(fa.verb)
+	if !ok {
+		return nil
+	}
+	vs.index = fa.index
+	return func(buf []byte, r *Info, pal *palette) []byte {
+		if fa.color && pal != nil {
+			buf = append(buf, pal.level(r.Level)...)
+		}
+		start := len(buf)
+		switch {
+		case fa.all && fa.json:
+			buf = appendFieldsJSON(buf, r.Fields)
+		case fa.all:
+			buf = finishString(appendFieldsText(buf, r.Fields), start, vs)
+		case fa.index > 0:
+			buf = appendValue(buf, r, vs)
+			if fa.json && (!numericValue(fa.index) || fa.index == 1 && r.UID != "") {
+				buf = appendJSONString(buf[:start], string(buf[start:]))
+			}
+		default:
+			f := r.field(fa.field)
+			switch {
+			case fa.json && f != nil:
+				buf = f.appendJSON(buf)
+			case fa.json:
+				buf = append(buf, "null"...)
+			case f != nil:
+				buf = finishString(f.appendTo(buf), start, vs)
+			default:
+				buf = finishString(buf, start, vs)
+			}
+		}
+		if fa.conv != nil {
+			buf = append(buf[:start], fa.conv(string(buf[start:]))...)
+		}
+		if fa.color && pal != nil {
+			buf = append(buf, "\033[0m"...)
+		}
+		return buf
+	}
+}
+
+// numericValue reports if the Info.Output argument is a number
+func numericValue(index int) bool {
+	switch index {
+	case 1, 6, 11, 15, 17:
+		return true
+	}
+	return false
+}
+
+// appendValue appends the Info.Output argument vs.index of the record
+func appendValue(buf []byte, r *Info, vs verbSpec) []byte {
+	switch vs.index {
+	case 1:
+		if r.UID != "" {
+			vs.prec, vs.zero = -1, false // never truncate or zero pad text ids
+			return appendString(buf, r.UID, vs)
+		}
+		return appendUint(buf, r.ID, vs)
+	case 2:
+		return timeOp(vs, defTimeFmt)(buf, r, nil)
+	case 3:
+		return appendString(buf, r.Module, vs)
+	case 4:
+		r.resolveCaller()
+		return appendString(buf, r.Function, vs)
+	case 5:
+		r.resolveCaller()
+		return appendString(buf, r.Filename, vs)
+	case 6:
+		r.resolveCaller()
+		return appendInt(buf, int64(r.Line), vs)
+	case 7:
+		return appendString(buf, r.logLevelString(), vs)
+	case 8:
+		return appendString(buf, r.Message, vs)
+	case 9:
+		return appendString(buf, r.Duration.String(), vs)
+	case 10:
+		return appendString(buf, r.Method, vs)
+	case 11:
+		return appendInt(buf, int64(r.StatusCode), vs)
+	case 12:
+		return appendString(buf, r.Route, vs)
+	case 13:
+		return appendString(buf, r.TraceID, vs)
+	case 14:
+		return appendString(buf, r.SpanID, vs)
+	case 15:
+		return appendInt(buf, int64(pid), vs)
+	case 16:
+		return appendString(buf, hostname, vs)
+	case 17:
+		return appendUint(buf, r.Goroutine, vs)
+	case 18:
+		return appendString(buf, r.Elapsed.String(), vs)
+	}
+	return buf
+}
+
+// appendString appends s truncated to vs.prec runes and padded to vs.width
+func appendString(buf []byte, s string, vs verbSpec) []byte {
+	start := len(buf)
+	return finishString(append(buf, s...), start, vs)
+}
+
+// finishString truncates the text appended to buf from start to vs.prec runes and pads it
+// to vs.width
+func finishString(buf []byte, start int, vs verbSpec) []byte {
+	if vs.prec >= 0 {
+		n := 0
+		for i := range string(buf[start:]) {
+			if n == vs.prec {
+				buf = buf[:start+i]
+				break
+			}
+			n++
+		}
+	}
+	return appendPadded(buf, start, vs)
+}
+
+// appendInt appends i with at least vs.prec digits, padded to vs.width
+func appendInt(buf []byte, i int64, vs verbSpec) []byte {
+	if i < 0 {
+		start := len(buf)
+		digits := vs
+		digits.width = 0
+		buf = appendUint(append(buf, '-'), uint64(-i), digits)
+		vs.zero = false
+		return appendPadded(buf, start, vs)
+	}
+	return appendUint(buf, uint64(i), vs)
+}
+
+// appendUint appends u with at least vs.prec digits, padded to vs.width
+func appendUint(buf []byte, u uint64, vs verbSpec) []byte {
+	start := len(buf)
+	if vs.prec > 0 {
+		var digits [20]byte
+		d := strconv.AppendUint(digits[:0], u, 10)
+		for n := len(d); n < vs.prec; n++ {
+			buf = append(buf, '0')
+		}
+		buf = append(buf, d...)
+	} else {
+		buf = strconv.AppendUint(buf, u, 10)
+	}
+	return appendPadded(buf, start, vs)
+}
+
+// appendAny appends a field value, using fmt only for types without a cheaper encoding
+func appendAny(buf []byte, v interface{}) []byte {
+	switch x := v.(type) {
+	case string:
+		return append(buf, x...)
+	case int:
+		return strconv.AppendInt(buf, int64(x), 10)
+	case int64:
+		return strconv.AppendInt(buf, x, 10)
+	case uint64:
+		return strconv.AppendUint(buf, x, 10)
+	case bool:
+		return strconv.AppendBool(buf, x)
+	case float64:
+		return strconv.AppendFloat(buf, x, 'g', -1, 64)
+	case error:
+		return append(buf, x.Error()...)
+	case fmt.Stringer:
+		return append(buf, x.String()...)
+	}
+	return fmt.Append(buf, v)
+}
+
+// appendPadded pads the value appended to buf from start up to vs.width runes. Zero padding
+// only applies to right aligned values, like printf
+func appendPadded(buf []byte, start int, vs verbSpec) []byte {
+	n := utf8.RuneCount(buf[start:])
+	if n >= vs.width {
+		return buf
+	}
+	pad := vs.width - n
+	if vs.left {
+		for ; pad > 0; pad-- {
+			buf = append(buf, ' ')
+		}
+		return buf
+	}
+
+	c := byte(' ')
+	if vs.zero {
+		c = '0'
+	}
+	end := len(buf)
+	for i := 0; i < pad; i++ {
+		buf = append(buf, c)
+	}
+	copy(buf[start+pad:], buf[start:end])
+	for i := start; i < start+pad; i++ {
+		buf[i] = c
+	}
+	return buf
+}
+
+// render appends the record to buf, coloring placeholders with pal unless it is nil
+func (rd *renderer) render(buf []byte, r *Info, pal *palette) []byte {
+	if pal == nil || !pal.themed {
+		for _, op := range rd.ops {
+			buf = op(buf, r, pal)
+		}
+		return buf
+	}
+
+	for i, op := range rd.ops {
+		style := pal.element(rd.elems[i], r.Level)
+		if style == "" {
+			buf = op(buf, r, pal)
+			continue
+		}
+		buf = append(buf, style...)
+		buf = op(buf, r, pal)
+		buf = append(buf, "\033[0m"...)
+	}
+	return buf
+}
@@ -0,0 +1,166 @@
+package golog
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+)
+
+func newRenderInfo() *Info {
+	return &Info{
+		ID:         42,
+		Time:       "2023-04-29 07:33:37",
+		Module:     "rendermodule-with-a-long-name",
+		Function:   "github.com/AndrewDonelson/golog.TestRender",
+		Filename:   "render_test.go",
+		Line:       12,
+		Level:      WarningLevel,
+		Message:    "héllo wörld",
+		Duration:   1500 * time.Millisecond,
+		Method:     "GET",
+		StatusCode: 404,
+		Route:      "/missing",
+		TraceID:    "4bf92f3577b34da6a3ce929d0e0e4736",
+		SpanID:     "00f067aa0ba902b7",
+		Goroutine:  7,
+		Elapsed:    time.Minute,
+	}
+}
+
+func TestRenderMatchesSprintf(t *testing.T) {
+	initFormatPlaceholders()
+	formats := []string{
+		FmtProductionLog,
+		FmtProductionJSON,
+		FmtDevelopmentLog,
+		"%[1]d %[2]s %[3]s %[4]s %[5]s %[6]d %[7]s %[8]s %[9]s %[10]s %[11]d %[12]s %[13]s %[14]s %[15]d %[16]s %[17]d %[18]s",
+		"%-20[3]s|%20[3]s|%.4[8]s|%-12.4[8]s|%08[1]d|%-8[6]d|%8.5[11]d|100%%",
+		"no verbs at all",
+	}
+
+	r := newRenderInfo()
+	for _, format := range formats {
+		if compileFormat(formatSpec{msgfmt: format}) == nil {
+			t.Errorf("Format %q did not compile", format)
+			continue
+		}
+		if want, have := r.sprintf(format), r.Output(format); want != have {
+			t.Errorf("\nWant: %s\nHave: %s", want, have)
+		}
+	}
+}
+
+func TestRenderFallback(t *testing.T) {
+	r := newRenderInfo()
+	for _, format := range []string{"%x", "%[3]q", "%[99]s", "%5"} {
+		if compileFormat(formatSpec{msgfmt: format}) != nil {
+			t.Errorf("Format %q should not compile", format)
+		}
+		if want, have := r.sprintf(format), r.Output(format); want != have {
+			t.Errorf("\nWant: %s\nHave: %s", want, have)
+		}
+	}
+}
+
+func TestRenderPlaceholderArgs(t *testing.T) {
+	initColors()
+	initFormatPlaceholders()
+	spec, err := parseFormat("%{module:-8:.4:upper}|%{field:user:.3}|%{field:n:04}|%{level:color}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rd := compileFormat(spec)
+
+	r := newRenderInfo()
+	r.Fields = []Field{String("user", "alexander"), {Key: "n", Value: 7}}
+	if have, want := string(rd.render(nil, r, nil)), "REND    |ale|0007|WARNING"; have != want {
+		t.Errorf("\nWant: %s\nHave: %s", want, have)
+	}
+	if have, want := string(rd.render(nil, r, palettes[ColorBasic])), "REND    |ale|0007|"+colors[WarningLevel]+"WARNING\033[0m"; have != want {
+		t.Errorf("\nWant: %q\nHave: %q", want, have)
+	}
+}
+
+/*********************** BENCHMARKS *****************************/
+func BenchmarkInfoSprintf(b *testing.B) {
+	r := newRenderInfo()
+	b.ReportAllocs()
+	for n := 0; n < b.N; n++ {
+		_ = r.sprintf(FmtDevelopmentLog)
+	}
+}
+
+func BenchmarkInfoRender(b *testing.B) {
+	r := newRenderInfo()
+	rd := compileFormat(formatSpec{msgfmt: FmtDevelopmentLog})
+	buf := make([]byte, 0, 256)
+	b.ReportAllocs()
+	for n := 0; n < b.N; n++ {
+		buf = rd.render(buf[:0], r, nil)
+	}
+}
+
+func BenchmarkLoggerLogDiscard(b *testing.B) {
+	log := NewLogger(&Options{Module: "BenchDiscard", Out: io.Discard})
+	log.SetEnvironment(EnvDevelopment)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		log.Info("Info Logging")
+	}
+}
+
+func BenchmarkLoggerDisabled(b *testing.B) {
+	log := NewLogger(&Options{Module: "BenchDisabled", Out: io.Discard})
+	log.SetEnvironment(EnvProduction)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		log.Debugf("Debug %s", "disabled")
+	}
+}
+
+func BenchmarkLoggerFields(b *testing.B) {
+	log := NewLogger(&Options{Module: "BenchFields", Out: io.Discard})
+	log.SetEnvironment(EnvDevelopment)
+	_ = log.SetFormat("%{time} %{lvl} %{field:user} %{field:n} %{message}")
+	log = log.With(String("user", "alice"), Int("n", 42))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		log.Info("Info Logging")
+	}
+}
+
+func TestFieldPlaceholders(t *testing.T) {
+	var buf bytes.Buffer
+	log := NewLogger(&Options{Module: "fields", Out: &buf})
+	log.SetEnvironment(EnvDevelopment)
+	log.SetColor(ClrDisabled)
+	if err := log.SetFormat(`{"level":%{level:json},"msg":%{message:json},"line":%{line:json},"user":%{field:user:json},"x":%{field:x:json},"fields":%{fields:json}}`); err != nil {
+		t.Fatal(err)
+	}
+
+	user := testUser{Name: "bob", Roles: testRoles{"ops"}}
+	log.With(Object("user", user), Int("n", 1)).Info("say \"hi\"")
+	line := bytes.TrimSpace(buf.Bytes())
+	if !json.Valid(line) {
+		t.Fatalf("Invalid JSON: %s", line)
+	}
+	var record map[string]interface{}
+	_ = json.Unmarshal(line, &record)
+	if record["msg"] != `say "hi"` || record["x"] != nil || record["user"].(map[string]interface{})["name"] != "bob" {
+		t.Errorf("Unexpected record: %s", line)
+	}
+	buf.Reset()
+
+	if err := log.SetFormat("%{message} %{fields}"); err != nil {
+		t.Fatal(err)
+	}
+	log.With(Object("user", user), Bool("ok", false)).Info("text")
+	if have, want := buf.String(), "text user={name=bob age=0 roles=[ops]} ok=false\n"; have != want {
+		t.Errorf("\nWant: %sHave: %s", want, have)
+	}
+}
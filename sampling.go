@@ -0,0 +1,77 @@
+// Package golog Simple flexible go logging
+// This file contains the code for sampling repeated records
+package golog
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// samplerBuckets is the number of counters per level, messages sharing a bucket are
+// counted together
+const samplerBuckets = 128
+
+// Sampling limits the records written for repeated messages: per Tick the first Initial
+// records of a level & message are written, then every Thereafter-th one (none if 0).
+// RawLevel records are never sampled
+type Sampling struct {
+	Initial    int
+	Thereafter int
+	Tick       time.Duration // defaults to a second
+}
+
+// samplerCounter counts the records of a bucket until resetAt
+type samplerCounter struct {
+	resetAt int64
+	count   uint64
+}
+
+// inc counts a record logged at now, restarting the count once the tick is over
+func (c *samplerCounter) inc(now, tick int64) uint64 {
+	if resetAt := atomic.LoadInt64(&c.resetAt); resetAt > now {
+		return atomic.AddUint64(&c.count, 1)
+	}
+	atomic.StoreUint64(&c.count, 1)
+	atomic.StoreInt64(&c.resetAt, now+tick)
+	return 1
+}
+
+// sampler applies a Sampling policy to the records of a worker
+type sampler struct {
+	policy   Sampling
+	counters [DebugLevel][samplerBuckets]samplerCounter
+}
+
+// newSampler returns a sampler for the policy, nil if records are not sampled
+func newSampler(policy *Sampling) *sampler {
+	if policy == nil {
+		return nil
+	}
+	s := &sampler{policy: *policy}
+	if s.policy.Tick <= 0 {
+		s.policy.Tick = time.Second
+	}
+	return s
+}
+
+// sample reports if the record is written
+func (s *sampler) sample(info *Info) bool {
+	if info.Level < RawLevel || info.Level > DebugLevel {
+		return true
+	}
+	// FNV-1a hash of the message
+	h := uint32(2166136261)
+	for i := 0; i < len(info.Message); i++ {
+		h = (h ^ uint32(info.Message[i])) * 16777619
+	}
+	now := info.Timestamp
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	n := s.counters[info.Level-1][h%samplerBuckets].inc(now.UnixNano(), int64(s.policy.Tick))
+	if n <= uint64(s.policy.Initial) {
+		return true
+	}
+	return s.policy.Thereafter > 0 && (n-uint64(s.policy.Initial))%uint64(s.policy.Thereafter) == 0
+}
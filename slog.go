@@ -0,0 +1,244 @@
+// Package golog Simple flexible go logging
+// This file contains the log/slog adapters
+package golog
+
+import (
+	"context"
+	"log/slog"
+)
+
+// SlogLevel returns the slog level of a golog level. Levels without a slog equivalent sit
+// between the slog levels: Notice & Success above Info, Trace between Warn & Error. The
+// verbosity of V(n) records is subtracted from LevelDebug
+func SlogLevel(level LogLevel, verbosity int) slog.Level {
+	switch level {
+	case ErrorLevel:
+		return slog.LevelError
+	case TraceLevel:
+		return slog.LevelWarn + 2
+	case WarningLevel:
+		return slog.LevelWarn
+	case SuccessLevel:
+		return slog.LevelInfo + 2
+	case NoticeLevel:
+		return slog.LevelInfo + 1
+	case DebugLevel:
+		return slog.LevelDebug - slog.Level(verbosity)
+	}
+	return slog.LevelInfo
+}
+
+// LevelFromSlog returns the golog level & verbosity of a slog level, the reverse of
+// SlogLevel. Levels below LevelDebug are Debug records with a verbosity
+func LevelFromSlog(level slog.Level) (LogLevel, int) {
+	switch {
+	case level >= slog.LevelError:
+		return ErrorLevel, 0
+	case level >= slog.LevelWarn+2:
+		return TraceLevel, 0
+	case level >= slog.LevelWarn:
+		return WarningLevel, 0
+	case level >= slog.LevelInfo+2:
+		return SuccessLevel, 0
+	case level > slog.LevelInfo:
+		return NoticeLevel, 0
+	case level > slog.LevelDebug:
+		return InfoLevel, 0
+	}
+	return DebugLevel, int(slog.LevelDebug - level)
+}
+
+// SlogHandler is a slog.Handler writing through a Logger, with its format, environment, level
+// overrides & sinks. Attributes become fields, groups become object fields
+type SlogHandler struct {
+	l      *Logger
+	fields []Field     // attributes added outside of any group
+	groups []slogGroup // groups opened by WithGroup, with the attributes added to them
+}
+
+// slogGroup is a group of attributes, encoded as an object field
+type slogGroup struct {
+	name  string
+	attrs []slog.Attr
+}
+
+// MarshalLogObject adds the attributes of the group as fields
+func (g slogGroup) MarshalLogObject(enc ObjectEncoder) error {
+	var fields []Field
+	for _, a := range g.attrs {
+		fields = appendAttrFields(fields, a)
+	}
+	for i := range fields {
+		if err := fields[i].encode(enc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NewSlogHandler returns a slog.Handler writing to the logger
+func NewSlogHandler(l *Logger) *SlogHandler {
+	return &SlogHandler{l: l}
+}
+
+// Slog returns a slog.Logger writing to the logger
+func (l *Logger) Slog() *slog.Logger {
+	return slog.New(NewSlogHandler(l))
+}
+
+// Enabled reports if the logger writes records of the level
+func (h *SlogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	lvl, v := LevelFromSlog(level)
+	return h.l.worker.enabled(lvl, v, h.l.Options.Module)
+}
+
+// Handle writes the record, correlated with the span of ctx
+func (h *SlogHandler) Handle(ctx context.Context, r slog.Record) error {
+	l := h.l
+	lvl, v := LevelFromSlog(r.Level)
+	info := l.newInfo(ctx, lvl, 2, r.Message)
+	info.pc, info.Verbosity = r.PC, v
+	info.Timestamp = r.Time // records without time have none, as slog handlers do
+
+	attrs := make([]slog.Attr, 0, r.NumAttrs())
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, a)
+		return true
+	})
+	info.Fields = h.fieldsOf(attrs)
+	l.write(info)
+	return nil
+}
+
+// fieldsOf returns the fields of a record: the logger's fields, the attributes of the handler
+// and the attributes of the record, nested in the groups of the handler
+func (h *SlogHandler) fieldsOf(attrs []slog.Attr) []Field {
+	fields := make([]Field, 0, len(h.l.fields)+len(h.fields)+len(attrs))
+	fields = append(fields, h.l.fields...)
+	fields = append(fields, h.fields...)
+	// wrap the attributes of the record in the groups, innermost first, omitting empty groups
+	for i := len(h.groups) - 1; i >= 0; i-- {
+		g := h.groups[i]
+		if g.attrs = append(g.attrs[:len(g.attrs):len(g.attrs)], attrs...); len(g.attrs) == 0 {
+			continue
+		}
+		attrs = []slog.Attr{{Key: g.name, Value: slog.GroupValue(g.attrs...)}}
+	}
+	for _, a := range attrs {
+		fields = appendAttrFields(fields, a)
+	}
+	return fields
+}
+
+// WithAttrs returns a handler adding the attributes to every record
+func (h *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	c := *h
+	if n := len(h.groups); n > 0 {
+		c.groups = append([]slogGroup(nil), h.groups...)
+		last := &c.groups[n-1]
+		last.attrs = append(last.attrs[:len(last.attrs):len(last.attrs)], attrs...)
+		return &c
+	}
+	c.fields = append([]Field(nil), h.fields...)
+	for _, a := range attrs {
+		c.fields = appendAttrFields(c.fields, a)
+	}
+	return &c
+}
+
+// WithGroup returns a handler nesting the attributes added afterwards in a group
+func (h *SlogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	c := *h
+	c.groups = append(h.groups[:len(h.groups):len(h.groups)], slogGroup{name: name})
+	return &c
+}
+
+// appendAttrFields appends the field of an attribute, or the fields of a group without a key.
+// Empty attributes & groups are dropped, groups become object fields
+func appendAttrFields(fields []Field, a slog.Attr) []Field {
+	v := a.Value.Resolve()
+	if v.Kind() == slog.KindGroup && a.Key == "" {
+		for _, ga := range v.Group() {
+			fields = appendAttrFields(fields, ga)
+		}
+		return fields
+	}
+	if f, ok := fieldOfAttr(a.Key, v); ok {
+		fields = append(fields, f)
+	}
+	return fields
+}
+
+// fieldOfAttr returns the field of a resolved attribute value
+func fieldOfAttr(key string, v slog.Value) (Field, bool) {
+	switch v.Kind() {
+	case slog.KindString:
+		return String(key, v.String()), true
+	case slog.KindInt64:
+		return Int64(key, v.Int64()), true
+	case slog.KindUint64:
+		return Uint64(key, v.Uint64()), true
+	case slog.KindFloat64:
+		return Float64(key, v.Float64()), true
+	case slog.KindBool:
+		return Bool(key, v.Bool()), true
+	case slog.KindDuration:
+		return Duration(key, v.Duration()), true
+	case slog.KindTime:
+		return Time(key, v.Time()), true
+	case slog.KindGroup:
+		if len(v.Group()) == 0 {
+			return Field{}, false
+		}
+		return Object(key, slogGroup{name: key, attrs: v.Group()}), true
+	}
+	if key == "" && v.Any() == nil {
+		return Field{}, false
+	}
+	return Any(key, v.Any()), true
+}
+
+// SlogSink is a Sink forwarding records to a slog.Handler. Fields become attributes and
+// the module, trace & span ids are added as the "module", "trace_id" & "span_id" attributes
+type SlogSink struct {
+	h slog.Handler
+}
+
+// NewSlogSink returns a sink forwarding records to the handler
+func NewSlogSink(h slog.Handler) *SlogSink {
+	return &SlogSink{h: h}
+}
+
+// Write forwards the record to the handler if it handles its level
+func (s *SlogSink) Write(info *Info) error {
+	level := SlogLevel(info.Level, info.Verbosity)
+	ctx := context.Background()
+	if !s.h.Enabled(ctx, level) {
+		return nil
+	}
+	r := slog.NewRecord(info.Timestamp, level, info.Message, info.pc)
+	if info.Module != "" {
+		r.AddAttrs(slog.String("module", info.Module))
+	}
+	if info.TraceID != "" {
+		r.AddAttrs(slog.String("trace_id", info.TraceID), slog.String("span_id", info.SpanID))
+	}
+	for i := range info.Fields {
+		r.AddAttrs(attrOfField(&info.Fields[i]))
+	}
+	return s.h.Handle(ctx, r)
+}
+
+// attrOfField returns the attribute of a field, object fields created from groups become groups
+func attrOfField(f *Field) slog.Attr {
+	if g, ok := f.Value.(slogGroup); ok && f.kind == objectField {
+		return slog.Attr{Key: f.Key, Value: slog.GroupValue(g.attrs...)}
+	}
+	return slog.Any(f.Key, f.Interface())
+}
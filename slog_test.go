@@ -0,0 +1,106 @@
+package golog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+	"testing/slogtest"
+	"time"
+)
+
+func TestSlogHandler(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewLogger(&Options{Module: "slog", Out: &buf, Levels: LevelOverrides{}})
+	l.SetEnvironment(EnvDevelopment)
+	_ = l.SetFormat("%{level} %{file}:%{line} %{message} %{fields}")
+
+	logger := l.With(String("app", "shop")).Slog().With("user", "ann").WithGroup("req")
+	logger.Info("checkout", "items", 3, slog.Group("card", "brand", "visa"))
+	logger.Warn("empty group", slog.Group("none"))
+	logger.Log(context.Background(), slog.LevelDebug-2, "verbose")
+
+	want := "INFO slog_test.go:21 checkout app=shop user=ann req={items=3 card={brand=visa}}\n" +
+		"WARNING slog_test.go:22 empty group app=shop user=ann\n"
+	if have := buf.String(); have != want {
+		t.Errorf("\nWant: %q\nHave: %q", want, have)
+	}
+
+	buf.Reset()
+	l.SetVerbosity(2)
+	logger.Log(context.Background(), slog.LevelDebug-2, "verbose")
+	if !strings.HasPrefix(buf.String(), "DEBUG slog_test.go:33 verbose") {
+		t.Errorf("Want a V(2) record Have: %q", buf.String())
+	}
+}
+
+func TestSlogHandlerConformance(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewLogger(&Options{Module: "slog", Out: &buf, Levels: LevelOverrides{}})
+	l.SetEnvironment(EnvDevelopment)
+	_ = l.SetFormat(`{"time":"%{time:2006-01-02T15:04:05Z07:00}","level":%{level:json},"msg":%{message:json},"fields":%{fields:json}}`)
+
+	results := func() []map[string]any {
+		var records []map[string]any
+		for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+			var record map[string]any
+			if err := json.Unmarshal([]byte(line), &record); err != nil {
+				t.Fatalf("Invalid JSON %q: %v", line, err)
+			}
+			if record[slog.TimeKey] == "" {
+				delete(record, slog.TimeKey)
+			}
+			for k, v := range record["fields"].(map[string]any) {
+				record[k] = v
+			}
+			delete(record, "fields")
+			records = append(records, record)
+		}
+		return records
+	}
+	if err := slogtest.TestHandler(NewSlogHandler(l), results); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSlogLevels(t *testing.T) {
+	for level := RawLevel; level <= DebugLevel; level++ {
+		want := LogLevel(level)
+		if level == RawLevel {
+			want = InfoLevel
+		}
+		if have, v := LevelFromSlog(SlogLevel(LogLevel(level), 0)); have != want || v != 0 {
+			t.Errorf("Level %d: Want: %d Have: %d (V%d)", level, want, have, v)
+		}
+	}
+	if level, v := LevelFromSlog(slog.LevelDebug - 3); level != DebugLevel || v != 3 {
+		t.Errorf("Want: Debug V3 Have: %d V%d", level, v)
+	}
+}
+
+func TestSlogSink(t *testing.T) {
+	var out bytes.Buffer
+	h := slog.NewTextHandler(&out, &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey && len(groups) == 0 {
+				return slog.Attr{}
+			}
+			return a
+		},
+	})
+
+	l := NewLogger(&Options{Module: "billing", Out: &bytes.Buffer{}, Levels: LevelOverrides{}})
+	l.SetEnvironment(EnvDevelopment)
+	l.AddSink(NewSlogSink(h))
+	l.With(Int("amount", 42), Duration("took", time.Second)).Notice("charged")
+	l.Slog().WithGroup("req").Info("grouped", "id", 7)
+
+	want := "level=INFO+1 msg=charged module=billing amount=42 took=1s\n" +
+		"level=INFO msg=grouped module=billing req.id=7\n"
+	if have := out.String(); have != want {
+		t.Errorf("\nWant: %q\nHave: %q", want, have)
+	}
+}
@@ -0,0 +1,102 @@
+// Package golog Simple flexible go logging
+// This file contains the bridge from the standard library log package
+package golog
+
+import (
+	"log"
+	"path"
+	"runtime"
+	"strings"
+)
+
+// stdLogWriter writes the lines of a standard library logger as records of a level
+type stdLogWriter struct {
+	l     *Logger
+	level LogLevel
+}
+
+// Write logs a line written by the log package, attributed to the caller of the log package
+func (w *stdLogWriter) Write(p []byte) (int, error) {
+	l := w.l
+	if !l.worker.enabled(w.level, 0, l.Options.Module) {
+		return len(p), nil
+	}
+	info := l.newInfo(nil, w.level, 2, trimStdLogHeader(strings.TrimSuffix(string(p), "\n")))
+	info.pc, info.resolved = 0, true
+	if frame, ok := stdLogCaller(); ok {
+		info.pc = frame.PC + 1 // return address, like the program counters of runtime.Callers
+		info.Function, info.Filename, info.Path, info.Line = frame.Function, path.Base(frame.File), frame.File, frame.Line
+	}
+	l.write(info)
+	return len(p), nil
+}
+
+// trimStdLogHeader removes the date & time added by loggers whose flags were set after
+// RedirectStdLog, as records carry their own
+func trimStdLogHeader(line string) string {
+	if matchLayout(line, "0000/00/00 ") {
+		line = line[11:]
+	}
+	if matchLayout(line, "00:00:00") {
+		rest := line[8:]
+		if strings.HasPrefix(rest, ".") {
+			rest = strings.TrimLeft(rest[1:], "0123456789")
+		}
+		if strings.HasPrefix(rest, " ") {
+			line = rest[1:]
+		}
+	}
+	return line
+}
+
+// matchLayout reports if s starts with the layout, where 0 matches any digit
+func matchLayout(s, layout string) bool {
+	if len(s) < len(layout) {
+		return false
+	}
+	for i := 0; i < len(layout); i++ {
+		if layout[i] == '0' && (s[i] < '0' || s[i] > '9') || layout[i] != '0' && s[i] != layout[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// stdLogCaller returns the first frame of the stack outside of the log package. Frames are
+// expanded so calls to the log package inlined in the caller are skipped too
+func stdLogCaller() (runtime.Frame, bool) {
+	var pcs [16]uintptr
+	n := runtime.Callers(3, pcs[:]) // skip Callers, stdLogCaller & Write
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, "log.") {
+			return frame, frame.Function != ""
+		}
+		if !more {
+			return runtime.Frame{}, false
+		}
+	}
+}
+
+// RedirectStdLog sends the output of the standard library log package (log.Print, log.Printf...)
+// to the logger at level, attributed to the callers of the log package. The flags & prefix of
+// the log package are cleared as the logger adds its own. The returned function restores the
+// previous output, flags & prefix
+func RedirectStdLog(l *Logger, level LogLevel) func() {
+	flags, prefix, out := log.Flags(), log.Prefix(), log.Writer()
+	log.SetFlags(0)
+	log.SetPrefix("")
+	log.SetOutput(&stdLogWriter{l: l, level: level})
+	return func() {
+		log.SetFlags(flags)
+		log.SetPrefix(prefix)
+		log.SetOutput(out)
+	}
+}
+
+// StdLogger returns a standard library logger writing to the logger at level, for APIs
+// requiring one such as http.Server.ErrorLog
+func (l *Logger) StdLogger(level LogLevel) *log.Logger {
+	return log.New(&stdLogWriter{l: l, level: level}, "", 0)
+}
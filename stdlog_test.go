@@ -0,0 +1,60 @@
+package golog
+
+import (
+	"bytes"
+	"log"
+	"testing"
+)
+
+func TestRedirectStdLog(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewLogger(&Options{Module: "stdlog", Out: &buf, Levels: LevelOverrides{}})
+	l.SetEnvironment(EnvDevelopment)
+	_ = l.SetFormat("%{level} %{file}:%{line} %{function} %{message}")
+
+	out := log.Writer()
+	restore := RedirectStdLog(l, WarningLevel)
+	log.Printf("disk %d%% full", 93)
+	log.SetFlags(log.LstdFlags | log.Lmicroseconds)
+	log.Print("flags set by a library")
+	restore()
+
+	want := "WARNING stdlog_test.go:17 github.com/AndrewDonelson/golog.TestRedirectStdLog disk 93% full\n" +
+		"WARNING stdlog_test.go:19 github.com/AndrewDonelson/golog.TestRedirectStdLog flags set by a library\n"
+	if have := buf.String(); have != want {
+		t.Errorf("\nWant: %q\nHave: %q", want, have)
+	}
+	if log.Flags() != log.LstdFlags || log.Writer() != out {
+		t.Errorf("Log package not restored, flags %d", log.Flags())
+	}
+}
+
+func TestStdLogger(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewLogger(&Options{Module: "stdlog", Out: &buf, Levels: LevelOverrides{}})
+	l.SetEnvironment(EnvProduction)
+	_ = l.SetFormat("%{level} %{file}:%{line} %{message}")
+
+	std := l.StdLogger(ErrorLevel)
+	std.Println("http: TLS handshake error")
+	l.StdLogger(DebugLevel).Println("filtered by the environment")
+
+	if want := "ERROR stdlog_test.go:39 http: TLS handshake error\n"; buf.String() != want {
+		t.Errorf("\nWant: %q\nHave: %q", want, buf.String())
+	}
+}
+
+func TestTrimStdLogHeader(t *testing.T) {
+	for line, want := range map[string]string{
+		"2009/01/23 01:23:23 message":        "message",
+		"2009/01/23 01:23:23.123123 message": "message",
+		"01:23:23 message":                   "message",
+		"2009/01/23 message":                 "message",
+		"2009 was a year":                    "2009 was a year",
+		"12:30 meeting":                      "12:30 meeting",
+	} {
+		if have := trimStdLogHeader(line); have != want {
+			t.Errorf("%q: Want: %q Have: %q", line, want, have)
+		}
+	}
+}
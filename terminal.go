@@ -0,0 +1,163 @@
+// Package golog Simple flexible go logging
+// This file contains the terminal capability detection & color palettes
+package golog
+
+import (
+	"io"
+	"os"
+	"strings"
+)
+
+// ColorSupport is the color capability of an output
+type ColorSupport int
+
+const (
+	// ColorNone - Output is not a terminal or colors are disabled
+	ColorNone ColorSupport = iota
+	// ColorBasic - The 16 ANSI colors
+	ColorBasic
+	// Color256 - The xterm 256 color palette
+	Color256
+	// ColorTrueColor - 24-bit RGB colors
+	ColorTrueColor
+)
+
+// palette holds the escape sequences coloring the records of each level, or with a theme
+// the elements of records
+type palette struct {
+	levels   [DebugLevel + 1]string
+	elements [elementCount]string // styles of the elements other than the level
+	themed   bool                 // color elements rather than whole lines
+}
+
+// level returns the escape sequence of a level
+func (p *palette) level(level LogLevel) string {
+	if level < 0 || int(level) >= len(p.levels) {
+		return ""
+	}
+	return p.levels[level]
+}
+
+// element returns the escape sequence of an element of a record of the level
+func (p *palette) element(e element, level LogLevel) string {
+	if e == levelElement {
+		return p.level(level)
+	}
+	return p.elements[e]
+}
+
+// palettes of each color support, indexed by ColorSupport
+var palettes = [...]*palette{
+	ColorBasic: {levels: [...]string{
+		RawLevel:     colorString(White),
+		ErrorLevel:   colorString(Red),
+		TraceLevel:   colorString(Magenta),
+		WarningLevel: colorString(Yellow),
+		SuccessLevel: colorString(Green),
+		NoticeLevel:  colorString(Blue),
+		InfoLevel:    colorString(White),
+		DebugLevel:   colorString(Cyan),
+	}},
+	Color256: {levels: [...]string{
+		RawLevel:     "\033[38;5;252m",
+		ErrorLevel:   "\033[38;5;196m",
+		TraceLevel:   "\033[38;5;170m",
+		WarningLevel: "\033[38;5;214m",
+		SuccessLevel: "\033[38;5;41m",
+		NoticeLevel:  "\033[38;5;33m",
+		InfoLevel:    "\033[38;5;255m",
+		DebugLevel:   "\033[38;5;44m",
+	}},
+	ColorTrueColor: {levels: [...]string{
+		RawLevel:     "\033[38;2;208;208;208m",
+		ErrorLevel:   "\033[38;2;255;85;85m",
+		TraceLevel:   "\033[38;2;215;135;215m",
+		WarningLevel: "\033[38;2;255;184;108m",
+		SuccessLevel: "\033[38;2;80;250;123m",
+		NoticeLevel:  "\033[38;2;98;160;234m",
+		InfoLevel:    "\033[38;2;248;248;242m",
+		DebugLevel:   "\033[38;2;139;233;253m",
+	}},
+}
+
+// DetectColorSupport returns the colors out can display. FORCE_COLOR ("1", "2" or "3" for
+// 16, 256 or truecolor, "0" disables) takes precedence over everything, then NO_COLOR &
+// TERM=dumb disable colors, and otherwise out must be a terminal. The palette is picked from
+// COLORTERM & TERM
+func DetectColorSupport(out io.Writer) ColorSupport {
+	if force, ok := os.LookupEnv("FORCE_COLOR"); ok {
+		switch strings.ToLower(force) {
+		case "0", "false":
+			return ColorNone
+		case "2":
+			return Color256
+		case "3":
+			return ColorTrueColor
+		}
+		return maxColorSupport(ColorBasic, terminalColorSupport())
+	}
+
+	if os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" || !isTerminal(out) {
+		return ColorNone
+	}
+	return terminalColorSupport()
+}
+
+// terminalColorSupport returns the colors of the terminal described by COLORTERM & TERM
+func terminalColorSupport() ColorSupport {
+	switch strings.ToLower(os.Getenv("COLORTERM")) {
+	case "truecolor", "24bit":
+		return ColorTrueColor
+	}
+	term := os.Getenv("TERM")
+	if strings.Contains(term, "truecolor") || strings.Contains(term, "24bit") || strings.Contains(term, "direct") {
+		return ColorTrueColor
+	}
+	if strings.Contains(term, "256color") {
+		return Color256
+	}
+	return ColorBasic
+}
+
+// maxColorSupport returns the larger of two color supports
+func maxColorSupport(a, b ColorSupport) ColorSupport {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// isTerminal reports if out is a character device, such as a terminal
+func isTerminal(out io.Writer) bool {
+	f, ok := out.(interface{ Stat() (os.FileInfo, error) })
+	if !ok {
+		return false
+	}
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// palette returns the palette records are colored with in the color mode, nil for no color.
+// ClrAuto colors only outputs supporting it, ClrEnabled always uses at least the 16 colors
+func (c *workerConfig) palette(clr ColorMode) *palette {
+	support := c.term
+	switch clr {
+	case ClrAuto:
+		if support == ColorNone {
+			return nil
+		}
+	case ClrEnabled:
+		support = maxColorSupport(ColorBasic, support)
+	default:
+		return nil
+	}
+	if pal := c.themed[support]; pal != nil {
+		return pal
+	}
+	return palettes[support]
+}
+
+// ColorSupport returns the colors detected for the output of the worker
+func (w *Worker) ColorSupport() ColorSupport {
+	return w.config().term
+}
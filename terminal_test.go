@@ -0,0 +1,83 @@
+package golog
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"testing"
+)
+
+func TestDetectColorSupport(t *testing.T) {
+	// /dev/null is a character device, like a terminal
+	tty, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		t.Skip(err)
+	}
+	defer tty.Close()
+	file, err := os.CreateTemp(t.TempDir(), "log")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	var tests = []struct {
+		env  map[string]string
+		out  io.Writer
+		want ColorSupport
+	}{
+		{map[string]string{"TERM": "xterm"}, tty, ColorBasic},
+		{map[string]string{"TERM": "xterm-256color"}, tty, Color256},
+		{map[string]string{"TERM": "xterm-256color", "COLORTERM": "truecolor"}, tty, ColorTrueColor},
+		{map[string]string{"TERM": "xterm"}, file, ColorNone},
+		{map[string]string{"TERM": "xterm"}, &bytes.Buffer{}, ColorNone},
+		{map[string]string{"TERM": "dumb"}, tty, ColorNone},
+		{map[string]string{"TERM": "xterm", "NO_COLOR": "1"}, tty, ColorNone},
+		{map[string]string{"TERM": "xterm", "FORCE_COLOR": "1"}, file, ColorBasic},
+		{map[string]string{"TERM": "xterm-256color", "FORCE_COLOR": ""}, file, Color256},
+		{map[string]string{"TERM": "dumb", "FORCE_COLOR": "3", "NO_COLOR": "1"}, file, ColorTrueColor},
+		{map[string]string{"TERM": "xterm", "FORCE_COLOR": "0"}, tty, ColorNone},
+	}
+
+	for i, test := range tests {
+		for _, key := range []string{"TERM", "COLORTERM", "NO_COLOR", "FORCE_COLOR"} {
+			t.Setenv(key, "")
+			os.Unsetenv(key)
+		}
+		for key, value := range test.env {
+			t.Setenv(key, value)
+		}
+		if have := DetectColorSupport(test.out); have != test.want {
+			t.Errorf("%d: Want %d Have %d", i, test.want, have)
+		}
+	}
+}
+
+func TestAutoColor(t *testing.T) {
+	t.Setenv("FORCE_COLOR", "")
+	os.Unsetenv("FORCE_COLOR")
+	t.Setenv("NO_COLOR", "")
+
+	var buf bytes.Buffer
+	log := NewLogger(&Options{Module: "autocolor", Out: &buf})
+	log.SetEnvironment(EnvDevelopment)
+	_ = log.SetFormat("%{level} %{message}")
+	log.Info("plain")
+	if have := buf.String(); have != "INFO plain\n" {
+		t.Errorf("Colored output to a buffer: %q", have)
+	}
+	buf.Reset()
+
+	t.Setenv("FORCE_COLOR", "2")
+	log.SetOutput(&buf)
+	log.Info("colored")
+	if have, want := buf.String(), palettes[Color256].level(InfoLevel)+"INFO colored\033[0m\n"; have != want {
+		t.Errorf("\nWant: %q\nHave: %q", want, have)
+	}
+	buf.Reset()
+
+	log.SetColor(ClrDisabled)
+	log.Info("disabled")
+	if have := buf.String(); have != "INFO disabled\n" {
+		t.Errorf("Colored output while disabled: %q", have)
+	}
+}
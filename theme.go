@@ -0,0 +1,368 @@
+// Package golog Simple flexible go logging
+// This file contains the color themes
+package golog
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// element is a part of a record that a theme styles
+type element uint8
+
+const (
+	noElement element = iota
+	timeElement
+	moduleElement
+	callerElement
+	levelElement
+	messageElement
+	elementCount
+)
+
+// elementOf returns the element rendered by an Info.Output argument
+func elementOf(index int) element {
+	switch index {
+	case 2:
+		return timeElement
+	case 3:
+		return moduleElement
+	case 4, 5, 6:
+		return callerElement
+	case 7:
+		return levelElement
+	case 8:
+		return messageElement
+	}
+	return noElement
+}
+
+// Style is the look of an element of a record. Color is a name ("red", "bright-blue"...),
+// an index of the 256 color palette ("208") or a RGB hex value ("#ff8800"), converted to the
+// closest color the output supports. An empty Color keeps the default color
+type Style struct {
+	Color     string `json:"color"`
+	Bold      bool   `json:"bold"`
+	Dim       bool   `json:"dim"`
+	Italic    bool   `json:"italic"`
+	Underline bool   `json:"underline"`
+}
+
+// Theme styles each element of a record instead of coloring the whole line with the color
+// of its level. Levels without a style use the default level colors
+type Theme struct {
+	Name    string
+	Levels  map[LogLevel]Style // style of %{level} & %{lvl}
+	Time    Style
+	Module  Style
+	Caller  Style // %{function}, %{file} & %{line}
+	Message Style
+}
+
+// Built-in themes
+var (
+	// ThemeDark suits terminals with a dark background
+	ThemeDark = &Theme{
+		Name: "dark",
+		Levels: map[LogLevel]Style{
+			ErrorLevel:   {Color: "#ff5555", Bold: true},
+			TraceLevel:   {Color: "#d787d7"},
+			WarningLevel: {Color: "#ffb86c", Bold: true},
+			SuccessLevel: {Color: "#50fa7b"},
+			NoticeLevel:  {Color: "#62a0ea"},
+			InfoLevel:    {Color: "#f8f8f2"},
+			DebugLevel:   {Color: "#8be9fd"},
+		},
+		Time:   Style{Color: "#6c7086"},
+		Module: Style{Color: "#89b4fa"},
+		Caller: Style{Color: "#6c7086", Italic: true},
+	}
+
+	// ThemeLight suits terminals with a light background
+	ThemeLight = &Theme{
+		Name: "light",
+		Levels: map[LogLevel]Style{
+			ErrorLevel:   {Color: "#c0392b", Bold: true},
+			TraceLevel:   {Color: "#8e44ad"},
+			WarningLevel: {Color: "#b7791f", Bold: true},
+			SuccessLevel: {Color: "#2f855a"},
+			NoticeLevel:  {Color: "#2b6cb0"},
+			InfoLevel:    {Color: "#1a202c"},
+			DebugLevel:   {Color: "#00838f"},
+		},
+		Time:   Style{Color: "#718096"},
+		Module: Style{Color: "#2b6cb0", Bold: true},
+		Caller: Style{Color: "#718096", Italic: true},
+	}
+
+	// ThemeHighContrast uses bold bright colors only
+	ThemeHighContrast = &Theme{
+		Name: "high-contrast",
+		Levels: map[LogLevel]Style{
+			ErrorLevel:   {Color: "bright-red", Bold: true},
+			TraceLevel:   {Color: "bright-magenta", Bold: true},
+			WarningLevel: {Color: "bright-yellow", Bold: true},
+			SuccessLevel: {Color: "bright-green", Bold: true},
+			NoticeLevel:  {Color: "bright-cyan", Bold: true},
+			InfoLevel:    {Color: "bright-white", Bold: true},
+			DebugLevel:   {Color: "bright-blue", Bold: true},
+		},
+		Time:    Style{Color: "bright-white"},
+		Module:  Style{Color: "bright-white", Bold: true, Underline: true},
+		Caller:  Style{Color: "bright-white"},
+		Message: Style{Color: "bright-white"},
+	}
+)
+
+// themes holds the themes by name
+var themes = struct {
+	sync.RWMutex
+	byName map[string]*Theme
+}{byName: map[string]*Theme{
+	ThemeDark.Name:         ThemeDark,
+	ThemeLight.Name:        ThemeLight,
+	ThemeHighContrast.Name: ThemeHighContrast,
+}}
+
+// RegisterTheme makes a theme available to LookupTheme, Logger.SetThemeFromString and
+// profiles, replacing any theme with the same name
+func RegisterTheme(theme *Theme) error {
+	if _, err := theme.compile(ColorTrueColor); err != nil {
+		return err
+	}
+	themes.Lock()
+	defer themes.Unlock()
+	themes.byName[strings.ToLower(theme.Name)] = theme
+	return nil
+}
+
+// LookupTheme returns the theme registered under name
+func LookupTheme(name string) (*Theme, bool) {
+	themes.RLock()
+	defer themes.RUnlock()
+	theme, ok := themes.byName[strings.ToLower(strings.TrimSpace(name))]
+	return theme, ok
+}
+
+// themeConfig is the JSON form of a Theme
+type themeConfig struct {
+	Name    string           `json:"name"`
+	Levels  map[string]Style `json:"levels"`
+	Time    Style            `json:"time"`
+	Module  Style            `json:"module"`
+	Caller  Style            `json:"caller"`
+	Message Style            `json:"message"`
+}
+
+// LoadThemes registers the themes of a JSON config holding a list of themes:
+//
+//	[{"name": "solarized", "levels": {"error": {"color": "#dc322f", "bold": true}},
+//	  "time": {"color": "#586e75"}, "module": {"color": "33"}, "message": {"color": "white"}}]
+func LoadThemes(r io.Reader) error {
+	var configs []themeConfig
+	if err := json.NewDecoder(r).Decode(&configs); err != nil {
+		return fmt.Errorf("golog: invalid themes config: %v", err)
+	}
+
+	for _, c := range configs {
+		theme := &Theme{Name: c.Name, Levels: map[LogLevel]Style{}, Time: c.Time, Module: c.Module, Caller: c.Caller, Message: c.Message}
+		for name, style := range c.Levels {
+			level, err := ParseLogLevel(name)
+			if err != nil {
+				return fmt.Errorf("golog: theme %q: %v", c.Name, err)
+			}
+			theme.Levels[level] = style
+		}
+		if err := RegisterTheme(theme); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// compile returns the palette of the theme for the colors an output supports
+func (t *Theme) compile(support ColorSupport) (*palette, error) {
+	if t.Name == "" {
+		return nil, fmt.Errorf("golog: theme has no name")
+	}
+	pal := &palette{themed: true}
+	if support > ColorNone {
+		pal.levels = palettes[support].levels
+	}
+	var err error
+	for level, style := range t.Levels {
+		if level < RawLevel || level > DebugLevel {
+			return nil, fmt.Errorf("golog: theme %q has a style for invalid level %d", t.Name, level)
+		}
+		if pal.levels[level], err = style.sequence(support); err != nil {
+			return nil, fmt.Errorf("golog: theme %q: %v", t.Name, err)
+		}
+	}
+	for e, style := range map[element]Style{timeElement: t.Time, moduleElement: t.Module, callerElement: t.Caller, messageElement: t.Message} {
+		if pal.elements[e], err = style.sequence(support); err != nil {
+			return nil, fmt.Errorf("golog: theme %q: %v", t.Name, err)
+		}
+	}
+	return pal, nil
+}
+
+// sequence returns the escape sequence of the style on an output with the color support
+func (s Style) sequence(support ColorSupport) (string, error) {
+	var params []string
+	if s.Bold {
+		params = append(params, "1")
+	}
+	if s.Dim {
+		params = append(params, "2")
+	}
+	if s.Italic {
+		params = append(params, "3")
+	}
+	if s.Underline {
+		params = append(params, "4")
+	}
+	if s.Color != "" {
+		color, err := colorParam(s.Color, support)
+		if err != nil {
+			return "", err
+		}
+		params = append(params, color)
+	}
+	if len(params) == 0 {
+		return "", nil
+	}
+	return "\033[" + strings.Join(params, ";") + "m", nil
+}
+
+// basicColors are the names & RGB values of the 16 ANSI colors, in SGR order
+var basicColors = [16]struct {
+	name    string
+	r, g, b int
+}{
+	{"black", 0, 0, 0}, {"red", 205, 0, 0}, {"green", 0, 205, 0}, {"yellow", 205, 205, 0},
+	{"blue", 0, 0, 238}, {"magenta", 205, 0, 205}, {"cyan", 0, 205, 205}, {"white", 229, 229, 229},
+	{"bright-black", 127, 127, 127}, {"bright-red", 255, 0, 0}, {"bright-green", 0, 255, 0}, {"bright-yellow", 255, 255, 0},
+	{"bright-blue", 92, 92, 255}, {"bright-magenta", 255, 0, 255}, {"bright-cyan", 0, 255, 255}, {"bright-white", 255, 255, 255},
+}
+
+// colorParam returns the SGR parameter of a color, converted to the closest color the
+// output supports
+func colorParam(color string, support ColorSupport) (string, error) {
+	color = strings.ToLower(strings.TrimSpace(color))
+	for i, c := range basicColors {
+		if c.name == color || (i == 8 && color == "gray") {
+			return basicParam(i), nil
+		}
+	}
+
+	var r, g, b int
+	switch {
+	case strings.HasPrefix(color, "#") && len(color) == 7:
+		rgb, err := strconv.ParseUint(color[1:], 16, 32)
+		if err != nil {
+			return "", fmt.Errorf("invalid color %q", color)
+		}
+		r, g, b = int(rgb>>16), int(rgb>>8&0xff), int(rgb&0xff)
+		if support == ColorTrueColor {
+			return fmt.Sprintf("38;2;%d;%d;%d", r, g, b), nil
+		}
+		if support == Color256 {
+			return "38;5;" + strconv.Itoa(cubeIndex(r, g, b)), nil
+		}
+	default:
+		n, err := strconv.Atoi(color)
+		if err != nil || n < 0 || n > 255 {
+			return "", fmt.Errorf("invalid color %q", color)
+		}
+		if n < 16 {
+			return basicParam(n), nil
+		}
+		if support >= Color256 {
+			return "38;5;" + color, nil
+		}
+		r, g, b = rgbOf256(n)
+	}
+	return basicParam(nearestBasic(r, g, b)), nil
+}
+
+// basicParam returns the SGR parameter of one of the 16 ANSI colors
+func basicParam(i int) string {
+	if i < 8 {
+		return strconv.Itoa(Black + i)
+	}
+	return strconv.Itoa(90 + i - 8)
+}
+
+// cubeIndex returns the index of the closest color of the 6x6x6 cube of the 256 colors
+func cubeIndex(r, g, b int) int {
+	level := func(v int) int {
+		if v < 48 {
+			return 0
+		}
+		if v < 115 {
+			return 1
+		}
+		return (v - 35) / 40
+	}
+	return 16 + 36*level(r) + 6*level(g) + level(b)
+}
+
+// rgbOf256 returns the RGB value of a color of the 256 colors palette above 15
+func rgbOf256(n int) (r, g, b int) {
+	if n >= 232 {
+		v := 8 + (n-232)*10
+		return v, v, v
+	}
+	n -= 16
+	value := func(c int) int {
+		if c == 0 {
+			return 0
+		}
+		return 55 + c*40
+	}
+	return value(n / 36), value(n / 6 % 6), value(n % 6)
+}
+
+// nearestBasic returns the index of the ANSI color closest to a RGB value
+func nearestBasic(r, g, b int) int {
+	best, dist := 0, -1
+	for i, c := range basicColors {
+		d := (r-c.r)*(r-c.r) + (g-c.g)*(g-c.g) + (b-c.b)*(b-c.b)
+		if dist < 0 || d < dist {
+			best, dist = i, d
+		}
+	}
+	return best
+}
+
+// SetTheme styles the elements of records with the theme, nil colors whole lines with the
+// color of their level. Use Logger.SetTheme to set it for a logger
+func (w *Worker) SetTheme(theme *Theme) error {
+	var err error
+	w.update(func(c *workerConfig) {
+		err = c.setTheme(theme)
+	})
+	return err
+}
+
+// setTheme compiles the palettes of the theme, keeping the current ones on error
+func (c *workerConfig) setTheme(theme *Theme) error {
+	if theme == nil {
+		c.themed = [len(palettes)]*palette{}
+		return nil
+	}
+	var themed [len(palettes)]*palette
+	for support := ColorBasic; int(support) < len(palettes); support++ {
+		pal, err := theme.compile(support)
+		if err != nil {
+			return err
+		}
+		themed[support] = pal
+	}
+	c.themed = themed
+	return nil
+}
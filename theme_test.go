@@ -0,0 +1,116 @@
+package golog
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestColorParam(t *testing.T) {
+	var tests = []struct {
+		color   string
+		support ColorSupport
+		want    string
+	}{
+		{"red", ColorTrueColor, "31"},
+		{"Bright-Blue", ColorBasic, "94"},
+		{"gray", Color256, "90"},
+		{"#ff8800", ColorTrueColor, "38;2;255;136;0"},
+		{"#ff8800", Color256, "38;5;208"},
+		{"#ff0000", ColorBasic, "91"},
+		{"208", Color256, "38;5;208"},
+		{"208", ColorBasic, "33"},
+		{"9", ColorBasic, "91"},
+		{"244", ColorBasic, "90"},
+	}
+	for _, test := range tests {
+		have, err := colorParam(test.color, test.support)
+		if err != nil || have != test.want {
+			t.Errorf("%s (%d): Want %s Have %s (%v)", test.color, test.support, test.want, have, err)
+		}
+	}
+
+	for _, color := range []string{"pink", "#12345", "#gggggg", "256", "-1"} {
+		if _, err := colorParam(color, ColorTrueColor); err == nil {
+			t.Errorf("%s: expected an error", color)
+		}
+	}
+
+	if have, _ := (Style{Color: "red", Bold: true, Underline: true}).sequence(ColorBasic); have != "\033[1;4;31m" {
+		t.Errorf("Unexpected sequence %q", have)
+	}
+	if have, _ := (Style{}).sequence(ColorBasic); have != "" {
+		t.Errorf("Unexpected sequence %q", have)
+	}
+}
+
+func TestTheme(t *testing.T) {
+	t.Setenv("FORCE_COLOR", "1")
+	var buf bytes.Buffer
+	log := NewLogger(&Options{Module: "themed", Out: &buf, Theme: ThemeHighContrast})
+	log.SetEnvironment(EnvDevelopment)
+	_ = log.SetFormat("%{time:15:04} %{module} %{level} %{file}: %{message}")
+
+	log.Error("themed")
+	have := buf.String()
+	for _, want := range []string{
+		"\033[97m",                       // time
+		"\033[1;4;97mthemed\033[0m ",     // module
+		"\033[1;91mERROR\033[0m ",        // level
+		"\033[97mtheme_test.go\033[0m: ", // caller, literals are not styled
+		"\033[97mthemed\033[0m\n",        // message
+	} {
+		if !strings.Contains(have, want) {
+			t.Errorf("%q does not contain %q", have, want)
+		}
+	}
+	buf.Reset()
+
+	if err := log.SetThemeFromString("nonexistent"); err == nil {
+		t.Error("Expected an error for an unknown theme")
+	}
+	if err := log.SetTheme(nil); err != nil {
+		t.Fatal(err)
+	}
+	log.Info("line")
+	if have := buf.String(); !strings.HasPrefix(have, colors[InfoLevel]) || strings.Count(have, "\033[0m") != 1 {
+		t.Errorf("Unexpected line coloring %q", have)
+	}
+}
+
+func TestLoadThemes(t *testing.T) {
+	config := `[{"name": "Solarized", "levels": {"error": {"color": "#dc322f", "bold": true}},
+		"time": {"color": "#586e75"}, "module": {"color": "33"}, "message": {"italic": true}}]`
+	if err := LoadThemes(strings.NewReader(config)); err != nil {
+		t.Fatal(err)
+	}
+	theme, ok := LookupTheme("solarized")
+	if !ok || !theme.Levels[ErrorLevel].Bold || theme.Module.Color != "33" {
+		t.Fatalf("Unexpected theme %+v", theme)
+	}
+
+	env, err := RegisterProfile(Profile{Name: "themed", Level: InfoLevel, Format: "%{module} %{message}", Color: ClrEnabled, Theme: "solarized"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	log := NewLogger(&Options{Module: "solarized", Out: &buf})
+	log.SetEnvironment(env)
+	log.Info("profile")
+	if have := buf.String(); !strings.Contains(have, "\033[3mprofile\033[0m") {
+		t.Errorf("Profile theme not applied: %q", have)
+	}
+
+	for _, config := range []string{
+		`[{"levels": {}}]`,
+		`[{"name": "bad", "levels": {"loud": {}}}]`,
+		`[{"name": "bad", "time": {"color": "pink"}}]`,
+	} {
+		if err := LoadThemes(strings.NewReader(config)); err == nil {
+			t.Errorf("%s: expected an error", config)
+		}
+	}
+	if _, err := RegisterProfile(Profile{Name: "themed", Level: InfoLevel, Theme: "nonexistent"}); err == nil {
+		t.Error("Expected an error for an unknown theme")
+	}
+}
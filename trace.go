@@ -0,0 +1,84 @@
+// Package golog Simple flexible go logging
+// This file contains the code for correlating log records with traces
+package golog
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+// spanContextKey is the context key under which the active span ids are stored
+type spanContextKey struct{}
+
+// spanContext holds the hex encoded ids of the active span
+type spanContext struct {
+	traceID string
+	spanID  string
+}
+
+// ContextWithSpan returns a copy of ctx carrying the given hex encoded trace & span ids.
+// Records logged with the returned context (see Logger.LogContext) will carry these ids
+func ContextWithSpan(ctx context.Context, traceID, spanID string) context.Context {
+	return context.WithValue(ctx, spanContextKey{}, spanContext{traceID: traceID, spanID: spanID})
+}
+
+// SpanFromContext returns the trace & span ids stored in ctx by ContextWithSpan
+func SpanFromContext(ctx context.Context) (traceID, spanID string, ok bool) {
+	sc, ok := ctx.Value(spanContextKey{}).(spanContext)
+	return sc.traceID, sc.spanID, ok
+}
+
+// TraceExtractor finds the hex encoded trace & span ids of the active span in a context.
+// Set one with Options.TraceExtractor to correlate with a tracing library (e.g. OpenTelemetry)
+type TraceExtractor func(ctx context.Context) (traceID, spanID string, ok bool)
+
+// TraceparentHeader is the W3C Trace Context request header
+const TraceparentHeader = "traceparent"
+
+// ParseTraceparent extracts the trace & span ids from a W3C traceparent header value
+// ("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+func ParseTraceparent(header string) (traceID, spanID string, ok bool) {
+	parts := strings.Split(strings.TrimSpace(header), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" {
+		return "", "", false
+	}
+	// version 00 has exactly four fields, future versions may append more
+	if parts[0] == "00" && len(parts) != 4 {
+		return "", "", false
+	}
+	if !isHex(parts[0], 2) || !isHex(parts[1], 32) || !isHex(parts[2], 16) || !isHex(parts[3], 2) {
+		return "", "", false
+	}
+	// all zero ids are invalid
+	if strings.Trim(parts[1], "0") == "" || strings.Trim(parts[2], "0") == "" {
+		return "", "", false
+	}
+	return parts[1], parts[2], true
+}
+
+// isHex reports if s is exactly n lowercase hex digits
+func isHex(s string, n int) bool {
+	if len(s) != n {
+		return false
+	}
+	for i := 0; i < n; i++ {
+		if c := s[i]; (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// RequestContext returns the context of r, carrying the span of its traceparent header
+// unless the context already holds one
+func RequestContext(r *http.Request) context.Context {
+	ctx := r.Context()
+	if _, _, ok := SpanFromContext(ctx); ok {
+		return ctx
+	}
+	if traceID, spanID, ok := ParseTraceparent(r.Header.Get(TraceparentHeader)); ok {
+		return ContextWithSpan(ctx, traceID, spanID)
+	}
+	return ctx
+}
@@ -0,0 +1,107 @@
+package golog
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestParseTraceparent(t *testing.T) {
+	var tests = []struct {
+		header  string
+		traceID string
+		spanID  string
+		ok      bool
+	}{
+		{"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", "4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7", true},
+		{"01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00-extra", "4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7", true},
+		{"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra", "", "", false},
+		{"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", "", "", false},
+		{"00-00000000000000000000000000000000-00f067aa0ba902b7-01", "", "", false},
+		{"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01", "", "", false},
+		{"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01", "", "", false},
+		{"00-4bf92f3577b34da6-00f067aa0ba902b7-01", "", "", false},
+		{"", "", "", false},
+	}
+
+	for _, test := range tests {
+		traceID, spanID, ok := ParseTraceparent(test.header)
+		if traceID != test.traceID || spanID != test.spanID || ok != test.ok {
+			t.Errorf("%q: Want: %s %s %v Have: %s %s %v", test.header, test.traceID, test.spanID, test.ok, traceID, spanID, ok)
+		}
+	}
+}
+
+func TestTracePlaceholders(t *testing.T) {
+	var buf bytes.Buffer
+	log := NewLogger(&Options{Module: "trace-ids", Out: &buf})
+	log.SetEnvironment(EnvDevelopment)
+	log.SetColor(ClrDisabled)
+	log.SetFormat("%{traceid}/%{spanid} %{message}")
+
+	ctx := ContextWithSpan(context.Background(), "4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7")
+	log.WithContext(ctx).Info("bound")
+	log.LogContext(ctx, InfoLevel, "explicit")
+	log.Info("none")
+
+	want := "4bf92f3577b34da6a3ce929d0e0e4736/00f067aa0ba902b7 bound\n" +
+		"4bf92f3577b34da6a3ce929d0e0e4736/00f067aa0ba902b7 explicit\n" +
+		"/ none\n"
+	if have := buf.String(); have != want {
+		t.Errorf("\nWant: %sHave: %s", want, have)
+	}
+}
+
+func TestTraceExtractor(t *testing.T) {
+	type key struct{}
+	var buf bytes.Buffer
+	log := NewLogger(&Options{Module: "trace-extractor", Out: &buf})
+	log.SetEnvironment(EnvDevelopment)
+	log.SetColor(ClrDisabled)
+	log.SetFormat("%{traceid}/%{spanid} %{message}")
+	log.SetTraceExtractor(func(ctx context.Context) (string, string, bool) {
+		id, ok := ctx.Value(key{}).(string)
+		return id, "0000000000000001", ok
+	})
+
+	log.LogContext(context.WithValue(context.Background(), key{}, "custom"), InfoLevel, "extracted")
+	if have, want := buf.String(), "custom/0000000000000001 extracted\n"; have != want {
+		t.Errorf("\nWant: %sHave: %s", want, have)
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	var buf bytes.Buffer
+	log := NewLogger(&Options{Module: "trace-middleware", Out: &buf})
+	log.SetEnvironment(EnvDevelopment)
+	log.SetColor(ClrDisabled)
+	log.SetFormat("%{level} %{traceid} %{spanid} %{message}")
+
+	handler := log.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		log.WithContext(r.Context()).Info("handling")
+		w.WriteHeader(http.StatusTeapot)
+	}))
+
+	req := httptest.NewRequest("GET", "/brew", nil)
+	req.Header.Set(TraceparentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusTeapot {
+		t.Errorf("Unexpected status: %d", rr.Code)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Want 2 lines, Have %d: %q", len(lines), buf.String())
+	}
+	if want := "INFO 4bf92f3577b34da6a3ce929d0e0e4736 00f067aa0ba902b7 handling"; lines[0] != want {
+		t.Errorf("\nWant: %s\nHave: %s", want, lines[0])
+	}
+	if want := "TRACE 4bf92f3577b34da6a3ce929d0e0e4736 00f067aa0ba902b7 GET /brew 418 "; !strings.HasPrefix(lines[1], want) {
+		t.Errorf("\nWant prefix: %s\nHave: %s", want, lines[1])
+	}
+}
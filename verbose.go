@@ -0,0 +1,60 @@
+// Package golog Simple flexible go logging
+// This file contains the code for glog style verbosity levels
+package golog
+
+import "fmt"
+
+// Verbose logs Debug level records of a verbosity, see Logger.V
+type Verbose struct {
+	l       *Logger
+	level   int
+	enabled bool
+}
+
+// V returns a Verbose logging Debug level records only written when the verbosity of the
+// logger (or of its module, see LevelOverrides) is at least level. The development
+// environment writes V(1), others none
+//
+//	log.V(2).Infof("cache %s: %d entries", name, len(entries))
+func (l *Logger) V(level int) Verbose {
+	return Verbose{l: l, level: level, enabled: l.worker.enabled(DebugLevel, level, l.Options.Module)}
+}
+
+// SetVerbosity sets the highest V(n) written, until the environment is changed
+func (l *Logger) SetVerbosity(v int) {
+	l.worker.SetVerbosity(v)
+}
+
+// logVerbose writes a Debug level record of verbosity v logged by the caller `pos` levels up
+func (l *Logger) logVerbose(v int, pos int, msg string) {
+	info := l.newInfo(nil, DebugLevel, pos+1, msg)
+	info.Verbosity = v
+	l.write(info)
+}
+
+// Enabled reports if records of the verbosity are written
+func (v Verbose) Enabled() bool {
+	return v.enabled
+}
+
+// Info logs a message if the verbosity is enabled
+func (v Verbose) Info(a ...interface{}) {
+	if v.enabled {
+		a, l := v.l.withArgFields(a)
+		l.logVerbose(v.level, 4, sprint(a))
+	}
+}
+
+// Infof logs a message using the same syntax and options as fmt.Printf if the verbosity is enabled
+func (v Verbose) Infof(format string, a ...interface{}) {
+	if v.enabled {
+		v.l.logVerbose(v.level, 4, fmt.Sprintf(format, a...))
+	}
+}
+
+// InfoFn logs the message returned by fn, which is only called if the verbosity is enabled
+func (v Verbose) InfoFn(fn func() string) {
+	if v.enabled {
+		v.l.logVerbose(v.level, 4, fn())
+	}
+}
@@ -0,0 +1,57 @@
+package golog
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestVerbosity(t *testing.T) {
+	var buf bytes.Buffer
+	log := NewLogger(&Options{Module: "verbose", Out: &buf, Levels: LevelOverrides{}})
+	setEnvironment := func(env Environment) {
+		log.SetEnvironment(env)
+		log.SetColor(ClrDisabled)
+		_ = log.SetFormat("%{module} %{lvl} %{message}")
+	}
+	setEnvironment(EnvDevelopment)
+
+	// development defaults to V(1)
+	log.V(1).Info("v1")
+	log.V(2).Info("hidden")
+	if log.V(2).Enabled() || !log.V(0).Enabled() {
+		t.Errorf("Unexpected Enabled")
+	}
+
+	log.SetVerbosity(3)
+	log.V(3).Infof("v%d", 3)
+	log.V(4).InfoFn(func() string {
+		t.Errorf("Disabled verbosity evaluated")
+		return ""
+	})
+
+	// verbosity sits under Debug
+	setEnvironment(EnvQuality)
+	log.SetVerbosity(3)
+	log.V(1).Info("hidden")
+
+	// per module & per file verbosity
+	if err := log.SetLevelOverrides("verb*=2"); err != nil {
+		t.Fatal(err)
+	}
+	log.V(2).Info("module v2")
+	log.V(3).Info("hidden")
+	if err := log.SetLevelOverrides("verbose_test.go=1,verbose=debug"); err != nil {
+		t.Fatal(err)
+	}
+	log.V(1).Info("file v1")
+	log.V(2).Info("hidden")
+	if err := log.SetLevelOverrides("other.go=1,verbose=debug"); err != nil {
+		t.Fatal(err)
+	}
+	log.V(3).Info("module keeps v3")
+
+	want := "verbose DEB v1\nverbose DEB v3\nverbose DEB module v2\nverbose DEB file v1\nverbose DEB module keeps v3\n"
+	if have := buf.String(); have != want {
+		t.Errorf("\nWant: %sHave: %s", want, have)
+	}
+}
@@ -3,83 +3,218 @@
 package golog
 
 import (
-	"bytes"
+	"errors"
 	"io"
 	"log"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
+// Sink receives every record that passes the level filter of a Worker, in addition
+// to the formatted output written to the Minion. Sinks that hold resources should
+// also implement io.Closer so they are released by Logger.Close
+type Sink interface {
+	Write(info *Info) error
+}
+
 // Worker class, Worker is a log object used to log messages and Color specifies
-// if colored output is to be produced
+// if colored output is to be produced. Its configuration can be changed while records are
+// logged on other goroutines: setters publish a new workerConfig and every record is written
+// with the configuration loaded when it is logged
 type Worker struct {
-	Minion      *log.Logger
-	environment Environment
-	color       ColorMode
-	format      string
-	timeFormat  string
-	level       LogLevel
-	function    string
+	Minion *log.Logger
+	cfg    atomic.Pointer[workerConfig] // replaced on change, so records are written without locking
+	cfgMu  sync.Mutex                   // serializes changes of cfg
+	errors writeErrors                  // failed writes of the output & sinks
+	mu     sync.Mutex                   // serializes writes bypassing the Minion
+}
+
+// workerConfig is the configuration of a Worker. A published configuration is never modified
+type workerConfig struct {
+	environment  Environment
+	color        ColorMode
+	term         ColorSupport            // colors of the output, used by ClrAuto
+	themed       [len(palettes)]*palette // palettes of the theme by color support, nil without theme
+	format       string
+	timeFormat   string
+	renderer     *renderer // format compiled by SetFormat
+	goroutine    bool      // format uses %{goroutine}
+	level        LogLevel
+	verbosity    int            // highest V(n) written at Debug level
+	overrides    LevelOverrides // per module & per file levels
+	function     string
+	sinks        []Sink
+	profileSinks []Sink          // sinks of the environment profile
+	sampler      *sampler        // sampling of the environment profile, nil writes every record
+	metrics      *Metrics        // nil counts nothing
+	recorder     *FlightRecorder // keeps the last records, written or not, nil keeps none
+	errHandler   func(err error) // called on failed writes, nil only counts them
+	fallback     io.Writer       // receives the records the output failed to write, nil drops them
 }
 
 // NewWorker Returns an instance of worker class, prefix is the string attached to every log,
 // flag determine the log params, color parameters verifies whether we need colored outputs or not
 func NewWorker(prefix string, flag int, color ColorMode, out io.Writer) *Worker {
-	return &Worker{Minion: log.New(out, prefix, flag), color: color, format: defFmt, timeFormat: defTimeFmt}
+	w := &Worker{Minion: log.New(out, prefix, flag)}
+	c := &workerConfig{color: color, term: DetectColorSupport(out), timeFormat: defTimeFmt}
+	c.setPrintfFormat(defFmt)
+	w.cfg.Store(c)
+	return w
+}
+
+// config returns the current configuration of the worker
+func (w *Worker) config() *workerConfig {
+	return w.cfg.Load()
+}
+
+// update publishes a copy of the configuration changed by change
+func (w *Worker) update(change func(c *workerConfig)) {
+	w.cfgMu.Lock()
+	defer w.cfgMu.Unlock()
+	c := *w.cfg.Load()
+	change(&c)
+	w.cfg.Store(&c)
 }
 
 // UseJSONForProduction forces using JSON instead of log for production
 func (w *Worker) UseJSONForProduction() {
-	if w.environment == EnvProduction {
-		w.format = FmtProductionJSON
-		w.color = ClrDisabled
+	w.update(func(c *workerConfig) {
+		if c.environment == EnvProduction {
+			c.setPrintfFormat(FmtProductionJSON)
+			c.color = ClrDisabled
+		}
+	})
+}
+
+// SetFormat parses the placeholders of format, keeping the current format on error
+func (w *Worker) SetFormat(format string) error {
+	var err error
+	w.update(func(c *workerConfig) {
+		err = c.setFormat(format)
+	})
+	return err
+}
+
+// setFormat parses the placeholders of format, keeping the current format on error
+func (c *workerConfig) setFormat(format string) error {
+	spec, err := parseFormat(format)
+	if err != nil {
+		return err
 	}
+	c.format, c.timeFormat, c.goroutine = spec.msgfmt, spec.timefmt, spec.goroutine
+	c.renderer = compileFormat(spec)
+	return nil
 }
 
-// SetFormat ...
-func (w *Worker) SetFormat(format string) {
-	w.format, w.timeFormat = parseFormat(format)
+// setPrintfFormat sets one of the built-in printf formats
+func (c *workerConfig) setPrintfFormat(format string) {
+	c.format, c.goroutine = format, false
+	if c.renderer = compileFormat(formatSpec{msgfmt: format}); c.renderer == nil {
+		c.renderer = sprintfRenderer(format)
+	}
 }
 
 // SetLogLevel ...
 func (w *Worker) SetLogLevel(level LogLevel) {
-	w.level = level
+	w.update(func(c *workerConfig) {
+		c.level = level
+	})
+}
+
+// SetVerbosity sets the highest V(n) written at Debug level
+func (w *Worker) SetVerbosity(v int) {
+	if v < 0 {
+		v = 0
+	}
+	w.update(func(c *workerConfig) {
+		c.verbosity = v
+	})
 }
 
 // SetFunction sets the function name ofr the worker
 func (w *Worker) SetFunction(name string) {
-	w.function = name
+	w.update(func(c *workerConfig) {
+		c.function = name
+	})
+}
+
+// SetColor sets the color mode of the worker
+func (w *Worker) SetColor(color ColorMode) {
+	w.update(func(c *workerConfig) {
+		c.color = color
+	})
 }
 
 // GetEnvironment returns the currently set environment for the worker
 func (w *Worker) GetEnvironment() Environment {
-	return w.environment
+	return w.config().environment
 }
 
-// SetEnvironment is used to manually set the log environment to either development, testing or production
+// SetEnvironment is used to manually set the log environment to one of the registered
+// profiles (see RegisterProfile), unknown environments use the production profile
 func (w *Worker) SetEnvironment(env Environment) {
-	w.environment = env
-	if env == EnvQuality {
-		// set for qa
-		w.level = InfoLevel
-		w.format = FmtProductionLog
-		w.color = ClrAuto
-		return
-	} else if env == EnvDevelopment {
-		// set for developer
-		w.level = DebugLevel
-		w.format = FmtDevelopmentLog
-		w.color = ClrAuto
-		return
-	}
-
-	// set for production
-	w.level = SuccessLevel
-	w.format = FmtProductionLog
-	w.color = ClrAuto
+	p, ok := profiles.profile(env)
+	if !ok {
+		p, _ = profiles.profile(EnvProduction)
+	}
+	w.update(func(c *workerConfig) {
+		c.environment = env
+		c.applyProfile(p)
+	})
+	for _, s := range p.Sinks {
+		w.reportSinkErrors(s)
+	}
 }
 
 // SetOutput is used to manually set the output to send log data
 func (w *Worker) SetOutput(out io.Writer) {
-	w.Minion.SetOutput(out)
+	w.update(func(c *workerConfig) {
+		w.Minion.SetOutput(out)
+		c.term = DetectColorSupport(out)
+	})
+}
+
+// AddSink registers a sink that receives every record written by the worker
+func (w *Worker) AddSink(s Sink) {
+	w.reportSinkErrors(s)
+	w.update(func(c *workerConfig) {
+		c.sinks = append(c.sinks[:len(c.sinks):len(c.sinks)], s)
+	})
+}
+
+// RemoveSink unregisters a sink added with AddSink
+func (w *Worker) RemoveSink(s Sink) {
+	w.update(func(c *workerConfig) {
+		sinks := make([]Sink, 0, len(c.sinks))
+		for _, sink := range c.sinks {
+			if sink != s {
+				sinks = append(sinks, sink)
+			}
+		}
+		c.sinks = sinks
+	})
+}
+
+// reportSinkErrors reports the failures of a sink writing in the background to the worker
+func (w *Worker) reportSinkErrors(s Sink) {
+	if r, ok := s.(errorReporter); ok {
+		name := sinkName(s)
+		r.reportErrors(func(err error) { w.fail(w.config(), name, err) })
+	}
+}
+
+// Close closes all sinks implementing io.Closer and returns the first error
+func (w *Worker) Close() error {
+	var first error
+	for _, s := range w.config().sinks {
+		if c, ok := s.(io.Closer); ok {
+			if err := c.Close(); err != nil && first == nil {
+				first = err
+			}
+		}
+	}
+	return first
 }
 
 // Log Function of Worker class to log a string based on level
@@ -91,29 +226,114 @@ func (w *Worker) Log(level LogLevel, calldepth int, info *Info) {
 	}
 
 	// Support RawLevel on any environment
-	clr := w.color
+	c := w.config()
+	clr := c.color
 	if level != RawLevel {
-		if w.level < level {
+		if lvl, v := c.levelFor(info); !allows(lvl, v, level, info.Verbosity) {
+			c.recorder.keep(info, false)
+			return
+		}
+		if c.sampler != nil && !c.sampler.sample(info) {
+			if c.metrics != nil {
+				c.metrics.sample(info)
+				c.metrics.drop(dropSampled)
+			}
+			c.recorder.keep(info, false)
 			return
 		}
 	} else {
 		clr = ClrDisabled
 	}
 
-	// Color for supported Levels
-	if clr == ClrAuto || clr == ClrEnabled {
-		buf := &bytes.Buffer{}
-		buf.Write([]byte(colors[level]))
-		buf.Write([]byte(info.Output(w.format)))
-		buf.Write([]byte("\033[0m"))
-		_ = w.Minion.Output(calldepth+1, buf.String())
-		return
+	if c.metrics != nil {
+		c.metrics.record(info)
 	}
 
-	if len(w.function) > 0 {
-		info.Function = w.function
+	if len(c.function) > 0 {
+		info.resolveCaller()
+		info.Function = c.function
 	}
 
-	// Regular no color output
-	_ = w.Minion.Output(calldepth+1, info.Output(w.format))
+	if len(c.sinks) > 0 || len(c.profileSinks) > 0 {
+		info.resolveCaller()
+		if info.Time == "" {
+			info.Time = info.Timestamp.Format(c.timeFormat)
+		}
+		for _, s := range c.profileSinks {
+			w.writeSink(c, s, info)
+		}
+		for _, s := range c.sinks {
+			w.writeSink(c, s, info)
+		}
+	}
+	// the context of an error comes before it in the output
+	if level == ErrorLevel && c.recorder != nil {
+		w.dumpRecords(calldepth+1, c, c.recorder.unwritten())
+	}
+	c.recorder.keep(info, true)
+
+	bp := bufPool.Get().(*[]byte)
+	buf := c.render((*bp)[:0], info, clr)
+	var err error
+	if c.metrics != nil {
+		start := time.Now()
+		err = w.output(calldepth+1, buf)
+		c.metrics.write(OutputSink, time.Since(start), err)
+		if err != nil {
+			c.metrics.drop(dropOutputFailed)
+		}
+	} else {
+		err = w.output(calldepth+1, buf)
+	}
+	if err != nil {
+		w.fail(c, OutputSink, err)
+		w.errors.fallback(c, buf)
+	}
+	*bp = buf
+	bufPool.Put(bp)
+}
+
+// render appends the formatted record to buf. Whole lines are colored for supported levels,
+// unless the format or the theme colors placeholders itself
+func (c *workerConfig) render(buf []byte, info *Info, clr ColorMode) []byte {
+	pal := c.palette(clr)
+	if pal != nil && !pal.themed && !c.renderer.colorArgs {
+		buf = append(buf, pal.level(info.Level)...)
+		buf = c.renderer.render(buf, info, pal)
+		return append(buf, "\033[0m"...)
+	}
+	return c.renderer.render(buf, info, pal)
+}
+
+// writeSink writes a record to a sink, timing the write when metrics are set
+func (w *Worker) writeSink(c *workerConfig, s Sink, info *Info) {
+	var err error
+	if c.metrics == nil {
+		err = s.Write(info)
+	} else {
+		start := time.Now()
+		err = s.Write(info)
+		c.metrics.write(sinkName(s), time.Since(start), err)
+		if errors.Is(err, ErrOTLPQueueFull) {
+			c.metrics.drop(dropQueueFull)
+		}
+	}
+	if err != nil {
+		w.fail(c, sinkName(s), err)
+	}
+}
+
+// output writes a rendered record. Without flags & prefix the Minion would only append a
+// newline, so the record is written directly to avoid copying it into a string
+func (w *Worker) output(calldepth int, buf []byte) error {
+	if w.Minion.Flags() != 0 || w.Minion.Prefix() != "" {
+		return w.Minion.Output(calldepth+1, string(buf))
+	}
+	if len(buf) == 0 || buf[len(buf)-1] != '\n' {
+		buf = append(buf, '\n')
+	}
+	w.mu.Lock()
+	_, err := w.Minion.Writer().Write(buf)
+	w.mu.Unlock()
+	return err
 }
@@ -0,0 +1,98 @@
+package golog
+
+import (
+	"io"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+type countingSink struct {
+	n *int64
+}
+
+func (s countingSink) Write(info *Info) error {
+	atomic.AddInt64(s.n, 1)
+	return nil
+}
+
+func TestSinksConcurrent(t *testing.T) {
+	log := NewLogger(&Options{Module: "test-sinks", Out: io.Discard, IDs: IDPerLogger})
+	log.SetEnvironment(EnvDevelopment)
+
+	var n int64
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				log.Info("concurrent")
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				s := countingSink{&n}
+				log.AddSink(s)
+				log.RemoveSink(s)
+			}
+		}()
+	}
+	wg.Wait()
+
+	log.AddSink(countingSink{&n})
+	before := atomic.LoadInt64(&n)
+	log.Info("after")
+	if have := atomic.LoadInt64(&n) - before; have != 1 {
+		t.Errorf("\nWant: %d\nHave: %d", 1, have)
+	}
+}
+
+func TestConfigConcurrent(t *testing.T) {
+	log := NewLogger(&Options{Module: "test-config", Out: io.Discard, IDs: IDPerLogger})
+	log.SetEnvironment(EnvDevelopment)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				log.Info("concurrent")
+				log.Error("failed")
+			}
+		}()
+	}
+	for j := 0; j < 20; j++ {
+		log.SetEnvironment(EnvQuality)
+		_ = log.SetFormat("%{level} %{message}")
+		log.SetMetrics(NewMetrics())
+		log.SetFlightRecorder(NewFlightRecorder(10))
+		log.SetErrorHandler(func(err error) {})
+		log.SetFallback(io.Discard)
+		log.SetColor(ClrDisabled)
+		_ = log.SetLevelOverrides("test-*=debug")
+		log.SetEnvironment(EnvDevelopment)
+	}
+	wg.Wait()
+}
+
+func TestFlightRecorderConcurrent(t *testing.T) {
+	fr := NewFlightRecorder(10)
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		l := NewLogger(&Options{Module: "flight", Out: io.Discard, IDs: IDPerLogger})
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				l.SetFlightRecorder(fr)
+				l.Error("failed")
+				_, _ = fr.WriteTo(io.Discard)
+				fr.Dump()
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,297 @@
+// Package golog Simple flexible go logging
+// This file contains the handling of failed writes to the output & sinks
+package golog
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// WriteError is a failed write of a record to the output (OutputSink) or to a sink
+type WriteError struct {
+	Sink string // OutputSink or the type of the sink, like the sink label of Metrics
+	Err  error
+}
+
+// Error returns the sink & the error of the write
+func (e *WriteError) Error() string {
+	return "golog: write to " + e.Sink + ": " + e.Err.Error()
+}
+
+// Unwrap returns the error of the write
+func (e *WriteError) Unwrap() error {
+	return e.Err
+}
+
+// errorReporter is implemented by sinks failing after Write returned, like OTLPExporter
+// exporting in the background. AddSink passes them the report of the worker
+type errorReporter interface {
+	reportErrors(report func(err error))
+}
+
+// writeErrors counts the failed writes of a worker
+type writeErrors struct {
+	count uint64
+	last  atomic.Value // *WriteError
+	mu    sync.Mutex   // serializes writes to the fallback
+}
+
+// fail records a failed write and reports it to the error handler of the configuration
+func (w *Worker) fail(c *workerConfig, sink string, err error) {
+	werr := &WriteError{Sink: sink, Err: err}
+	atomic.AddUint64(&w.errors.count, 1)
+	w.errors.last.Store(werr)
+	if c.errHandler != nil {
+		c.errHandler(werr)
+	}
+}
+
+// fallback writes a rendered record to the fallback writer of the configuration, if any. Its
+// failures are ignored as there is nowhere left to report them
+func (e *writeErrors) fallback(c *workerConfig, buf []byte) {
+	if c.fallback == nil {
+		return
+	}
+	if len(buf) == 0 || buf[len(buf)-1] != '\n' {
+		buf = append(buf, '\n')
+	}
+	e.mu.Lock()
+	_, _ = c.fallback.Write(buf)
+	e.mu.Unlock()
+}
+
+// SetErrorHandler calls handler with a *WriteError each time the output or a sink fails to
+// write a record, on the goroutine logging it or, for the background exports of sinks like
+// OTLPExporter, on the goroutine of the sink. nil only counts the failures (see ErrorCount)
+func (l *Logger) SetErrorHandler(handler func(err error)) {
+	l.worker.update(func(c *workerConfig) {
+		c.errHandler = handler
+	})
+}
+
+// SetFallback writes the formatted records the output failed to write to out, like os.Stderr
+// when the output is a file on a full disk. Records a sink failed to write are only reported,
+// as the output has them. nil drops them
+func (l *Logger) SetFallback(out io.Writer) {
+	l.worker.update(func(c *workerConfig) {
+		c.fallback = out
+	})
+}
+
+// ErrorCount returns the number of failed writes of the output & sinks of the logger
+func (l *Logger) ErrorCount() uint64 {
+	return atomic.LoadUint64(&l.worker.errors.count)
+}
+
+// LastError returns the last failed write of the output or sinks of the logger as a
+// *WriteError, nil if none failed
+func (l *Logger) LastError() error {
+	if err, ok := l.worker.errors.last.Load().(*WriteError); ok {
+		return err
+	}
+	return nil
+}
+
+// Backoff is the retry policy of a RetrySink: a failed write is tried again after Initial,
+// then after twice the previous delay up to Max, until Attempts writes failed
+type Backoff struct {
+	Attempts   int           // writes of a record, defaults to 3
+	Initial    time.Duration // defaults to 100ms
+	Max        time.Duration // defaults to 5s
+	MaxPending int           // records waiting for a retry, defaults to 1000
+}
+
+// ErrRetryQueueFull is returned by RetrySink.Write when MaxPending records wait for a retry,
+// wrapping the error of the last write
+var ErrRetryQueueFull = errors.New("golog: retry queue full")
+
+// retryEntry is a copy of a record waiting for a retry
+type retryEntry struct {
+	info     Info
+	attempts int           // failed writes so far
+	delay    time.Duration // wait before the next write
+}
+
+// RetrySink retries the failed writes of a sink, like a network sink, with exponential
+// backoff. A record is written once by Write, failed records are retried by a background
+// goroutine so logging is not blocked, and later records are queued behind them to keep
+// their order. Records failing all attempts are reported to the write errors of the logger
+// it was added to (see SetErrorHandler)
+type RetrySink struct {
+	Sink    Sink
+	backoff Backoff
+	wait    func(d time.Duration) bool // waits before a retry, false when the sink is closed
+	mu      sync.Mutex
+	queue   []retryEntry
+	err     error           // error of the last write
+	report  func(err error) // reports the records failing all attempts, nil ignores them
+	wake    chan struct{}
+	done    chan struct{}
+	wg      sync.WaitGroup
+	once    sync.Once
+}
+
+// NewRetrySink returns a sink retrying the failed writes of s with the backoff policy and
+// starts its background retries. Release it with Logger.Close
+func NewRetrySink(s Sink, backoff Backoff) *RetrySink {
+	if backoff.Attempts <= 0 {
+		backoff.Attempts = 3
+	}
+	if backoff.Initial <= 0 {
+		backoff.Initial = 100 * time.Millisecond
+	}
+	if backoff.Max <= 0 {
+		backoff.Max = 5 * time.Second
+	}
+	if backoff.MaxPending <= 0 {
+		backoff.MaxPending = 1000
+	}
+	r := &RetrySink{
+		Sink:    s,
+		backoff: backoff,
+		wake:    make(chan struct{}, 1),
+		done:    make(chan struct{}),
+	}
+	r.wait = r.sleep
+	r.wg.Add(1)
+	go r.run()
+	return r
+}
+
+// Write writes the record to the sink, or queues it for a retry if the write fails or
+// records are waiting for one. It fails with ErrRetryQueueFull, without queuing the record,
+// when MaxPending records wait for a retry
+func (r *RetrySink) Write(info *Info) error {
+	r.mu.Lock()
+	if len(r.queue) > 0 {
+		err := r.enqueue(retryEntry{info: *info})
+		r.mu.Unlock()
+		return err
+	}
+	r.mu.Unlock()
+
+	err := r.Sink.Write(info)
+	if err == nil || r.backoff.Attempts == 1 {
+		return err
+	}
+	r.mu.Lock()
+	r.err = err
+	err = r.enqueue(retryEntry{info: *info, attempts: 1, delay: r.backoff.Initial})
+	r.mu.Unlock()
+	return err
+}
+
+// enqueue adds a record to the retry queue & wakes the retries, r.mu must be held
+func (r *RetrySink) enqueue(e retryEntry) error {
+	if len(r.queue) >= r.backoff.MaxPending {
+		if r.err == nil {
+			return ErrRetryQueueFull
+		}
+		return fmt.Errorf("%w: %v", ErrRetryQueueFull, r.err)
+	}
+	r.queue = append(r.queue, e)
+	select {
+	case r.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// run retries the queued records in order until the sink is closed
+func (r *RetrySink) run() {
+	defer r.wg.Done()
+	for {
+		r.mu.Lock()
+		if len(r.queue) == 0 {
+			r.mu.Unlock()
+			select {
+			case <-r.wake:
+				continue
+			case <-r.done:
+				return
+			}
+		}
+		e := r.queue[0]
+		r.mu.Unlock()
+
+		if e.delay > 0 && !r.wait(e.delay) {
+			return
+		}
+		err := r.Sink.Write(&e.info)
+
+		r.mu.Lock()
+		r.err = err
+		if err == nil || e.attempts+1 >= r.backoff.Attempts {
+			r.queue = r.queue[1:]
+		} else {
+			next := 2 * e.delay
+			if e.delay == 0 {
+				next = r.backoff.Initial // queued behind other records, not tried yet
+			} else if next > r.backoff.Max {
+				next = r.backoff.Max
+			}
+			r.queue[0].attempts++
+			r.queue[0].delay = next
+		}
+		report := r.report
+		r.mu.Unlock()
+
+		if err != nil && e.attempts+1 >= r.backoff.Attempts && report != nil {
+			report(err)
+		}
+	}
+}
+
+// sleep waits d, or until the sink is closed
+func (r *RetrySink) sleep(d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return true
+	case <-r.done:
+		return false
+	}
+}
+
+// reportErrors sets the report of the records failing all attempts, and passes it to the
+// sink if it fails in the background
+func (r *RetrySink) reportErrors(report func(err error)) {
+	r.mu.Lock()
+	r.report = report
+	r.mu.Unlock()
+	if e, ok := r.Sink.(errorReporter); ok {
+		e.reportErrors(report)
+	}
+}
+
+// Close stops the retries, writes the queued records once more and closes the sink if it
+// implements io.Closer. It returns the error of the last failed write if the sink doesn't
+func (r *RetrySink) Close() error {
+	r.once.Do(func() {
+		close(r.done)
+		r.wg.Wait()
+	})
+
+	r.mu.Lock()
+	queue := r.queue
+	r.queue = nil
+	r.mu.Unlock()
+
+	var err error
+	for i := range queue {
+		if werr := r.Sink.Write(&queue[i].info); werr != nil {
+			err = werr
+		}
+	}
+	if c, ok := r.Sink.(io.Closer); ok {
+		if cerr := c.Close(); cerr != nil {
+			return cerr
+		}
+	}
+	return err
+}
@@ -0,0 +1,153 @@
+package golog
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// fullDisk fails every write like a file on a full disk
+type fullDisk struct{}
+
+func (fullDisk) Write(p []byte) (int, error) {
+	return 0, syscall.ENOSPC
+}
+
+func TestWriteErrors(t *testing.T) {
+	var fallback bytes.Buffer
+	var handled []string
+	l := NewLogger(&Options{
+		Module:       "writer",
+		Out:          fullDisk{},
+		Levels:       LevelOverrides{},
+		ErrorHandler: func(err error) { handled = append(handled, err.Error()) },
+		Fallback:     &fallback,
+	})
+	l.SetEnvironment(EnvDevelopment)
+	_ = l.SetFormat("%{level} %{message}")
+	if l.LastError() != nil {
+		t.Error("Want no error before a write failed")
+	}
+
+	l.Info("saved")
+	l.AddSink(failingSink{})
+	l.SetOutput(&bytes.Buffer{})
+	l.Warning("kept")
+
+	if want := "INFO saved\n"; fallback.String() != want {
+		t.Errorf("\nWant: %q\nHave: %q", want, fallback.String())
+	}
+	want := []string{"golog: write to output: no space left on device", "golog: write to golog.failingSink: unavailable"}
+	if !reflect.DeepEqual(handled, want) {
+		t.Errorf("\nWant: %q\nHave: %q", want, handled)
+	}
+	if l.ErrorCount() != 2 {
+		t.Errorf("Want: 2 Have: %d", l.ErrorCount())
+	}
+	var werr *WriteError
+	if err := l.LastError(); !errors.As(err, &werr) || werr.Sink != "golog.failingSink" {
+		t.Errorf("Want the failure of the sink Have: %v", err)
+	}
+}
+
+// flakySink fails its first writes
+type flakySink struct {
+	failures int
+	writes   int
+}
+
+func (s *flakySink) Write(info *Info) error {
+	if s.writes++; s.writes <= s.failures {
+		return errors.New("connection refused")
+	}
+	return nil
+}
+
+func TestRetrySink(t *testing.T) {
+	waits := make(chan time.Duration, 10)
+	flaky := &flakySink{failures: 3}
+	r := NewRetrySink(flaky, Backoff{Attempts: 4, Initial: time.Second, Max: 3 * time.Second})
+	r.wait = func(d time.Duration) bool {
+		waits <- d
+		return true
+	}
+
+	if err := r.Write(&Info{}); err != nil {
+		t.Errorf("Want the failed write queued Have: %v", err)
+	}
+	var slept []time.Duration
+	for len(slept) < 3 {
+		slept = append(slept, <-waits)
+	}
+	if err := r.Close(); err != nil {
+		t.Errorf("Want the 4th attempt to succeed Have: %v", err)
+	}
+	if want := []time.Duration{time.Second, 2 * time.Second, 3 * time.Second}; !reflect.DeepEqual(slept, want) {
+		t.Errorf("\nWant: %v\nHave: %v", want, slept)
+	}
+	if flaky.writes != 4 {
+		t.Errorf("Want: 4 writes Have: %d", flaky.writes)
+	}
+
+	failures := make(chan error, 1)
+	flaky = &flakySink{failures: 5}
+	r = NewRetrySink(flaky, Backoff{Attempts: 4, Initial: time.Millisecond})
+	r.reportErrors(func(err error) { failures <- err })
+	if err := r.Write(&Info{}); err != nil {
+		t.Errorf("Want the failed write queued Have: %v", err)
+	}
+	if err := <-failures; err == nil || err.Error() != "connection refused" {
+		t.Errorf("Want the error of the last attempt Have: %v", err)
+	}
+	_ = r.Close()
+	if flaky.writes != 4 {
+		t.Errorf("Want an error after 4 attempts Have: %d writes", flaky.writes)
+	}
+}
+
+// messagesSink keeps the messages it writes after its first failures
+type messagesSink struct {
+	flakySink
+	messages []string
+}
+
+func (s *messagesSink) Write(info *Info) error {
+	if err := s.flakySink.Write(info); err != nil {
+		return err
+	}
+	s.messages = append(s.messages, info.Message)
+	return nil
+}
+
+func TestRetrySinkQueue(t *testing.T) {
+	sink := &messagesSink{flakySink: flakySink{failures: 1}}
+	r := NewRetrySink(sink, Backoff{MaxPending: 2})
+	release := make(chan struct{})
+	r.wait = func(d time.Duration) bool {
+		select {
+		case <-release:
+			return true
+		case <-r.done:
+			return false
+		}
+	}
+
+	for _, msg := range []string{"first", "second"} {
+		if err := r.Write(&Info{Message: msg}); err != nil {
+			t.Errorf("Want %s queued Have: %v", msg, err)
+		}
+	}
+	if err := r.Write(&Info{Message: "third"}); !errors.Is(err, ErrRetryQueueFull) {
+		t.Errorf("Want: %v Have: %v", ErrRetryQueueFull, err)
+	}
+	close(release)
+	if err := r.Close(); err != nil {
+		t.Errorf("Want the queued records written Have: %v", err)
+	}
+	if want := []string{"first", "second"}; !reflect.DeepEqual(sink.messages, want) {
+		t.Errorf("\nWant: %q\nHave: %q", want, sink.messages)
+	}
+}